@@ -0,0 +1,368 @@
+// Package balance splits a pool of players into teams of (nearly) equal size
+// so that the TrueSkill match quality between the teams is maximised.
+//
+// Small pools are searched exhaustively, larger pools are balanced with a
+// randomised local search that is deterministic for a given seed.
+package balance
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+// Default balancing configuration.
+const (
+	DefaultSeed       = 1
+	DefaultExactLimit = 12 // Largest pool searched exhaustively
+	DefaultRestarts   = 8  // Random restarts of the local search
+)
+
+var (
+	errTooFewTeams    = errors.New("at least two teams are required")
+	errTooFewPlayers  = errors.New("not enough players for the number of teams")
+	errInvalidParty   = errors.New("party contains an invalid or duplicate player index")
+	errPartiesDontFit = errors.New("parties do not fit in teams of equal size")
+)
+
+// Option represents a balancing option.
+type Option func(c *config)
+
+type config struct {
+	parties    [][]int
+	seed       int64
+	exactLimit int
+	restarts   int
+}
+
+// Party keeps the players with the provided indexes on the same team.
+func Party(players ...int) Option {
+	return func(c *config) {
+		c.parties = append(c.parties, players)
+	}
+}
+
+// Seed sets the seed of the local search.
+func Seed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// ExactLimit sets the largest number of players for which all partitions
+// are searched. Larger pools use local search.
+func ExactLimit(n int) Option {
+	return func(c *config) {
+		c.exactLimit = n
+	}
+}
+
+// Restarts sets the number of random restarts of the local search.
+func Restarts(n int) Option {
+	return func(c *config) {
+		c.restarts = n
+	}
+}
+
+// Result is a partition of the players into teams.
+type Result struct {
+	Teams   [][]int // Player indexes of each team
+	Quality float64 // Match quality between the teams
+}
+
+// Players returns the players of each team.
+func (r Result) Players(players []trueskill.Player) [][]trueskill.Player {
+	teams := make([][]trueskill.Player, len(r.Teams))
+	for i, t := range r.Teams {
+		for _, p := range t {
+			teams[i] = append(teams[i], players[p])
+		}
+	}
+	return teams
+}
+
+// Teams splits the players into the requested number of teams. Team sizes
+// differ by at most one player.
+func Teams(ts trueskill.Config, players []trueskill.Player, numTeams int, opts ...Option) (Result, error) {
+	c := config{
+		seed:       DefaultSeed,
+		exactLimit: DefaultExactLimit,
+		restarts:   DefaultRestarts,
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	if numTeams < 2 {
+		return Result{}, errTooFewTeams
+	}
+	if len(players) < numTeams {
+		return Result{}, errTooFewPlayers
+	}
+
+	units, err := buildUnits(len(players), c.parties)
+	if err != nil {
+		return Result{}, err
+	}
+
+	b := balancer{
+		ts:       ts,
+		players:  players,
+		units:    units,
+		capacity: capacities(len(players), numTeams),
+	}
+
+	var best assignment
+	if len(players) <= c.exactLimit {
+		best = b.exact()
+	} else {
+		best = b.localSearch(rand.New(rand.NewSource(c.seed)), c.restarts)
+	}
+	if best.teamOf == nil {
+		return Result{}, errPartiesDontFit
+	}
+
+	return b.result(best), nil
+}
+
+// buildUnits groups the players into units that are assigned to a team
+// together: the parties followed by every remaining player on its own.
+// Larger units come first, which prunes the search early.
+func buildUnits(n int, parties [][]int) ([][]int, error) {
+	inParty := make([]bool, n)
+	var units [][]int
+	for _, party := range parties {
+		if len(party) == 0 {
+			continue
+		}
+		for _, p := range party {
+			if p < 0 || p >= n || inParty[p] {
+				return nil, errInvalidParty
+			}
+			inParty[p] = true
+		}
+		units = append(units, append([]int(nil), party...))
+	}
+	for p := 0; p < n; p++ {
+		if !inParty[p] {
+			units = append(units, []int{p})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return len(units[i]) > len(units[j]) })
+
+	return units, nil
+}
+
+// capacities returns the team sizes, the first n%numTeams teams get one
+// additional player.
+func capacities(n, numTeams int) []int {
+	capacity := make([]int, numTeams)
+	for t := range capacity {
+		capacity[t] = n / numTeams
+		if t < n%numTeams {
+			capacity[t]++
+		}
+	}
+	return capacity
+}
+
+type balancer struct {
+	ts       trueskill.Config
+	players  []trueskill.Player
+	units    [][]int
+	capacity []int
+}
+
+// assignment maps every unit to a team.
+type assignment struct {
+	teamOf  []int
+	quality float64
+}
+
+func (b balancer) teams(teamOf []int) [][]trueskill.Player {
+	teams := make([][]trueskill.Player, len(b.capacity))
+	for u, t := range teamOf {
+		for _, p := range b.units[u] {
+			teams[t] = append(teams[t], b.players[p])
+		}
+	}
+	return teams
+}
+
+func (b balancer) quality(teamOf []int) float64 {
+	return b.ts.TeamMatchQuality(b.teams(teamOf))
+}
+
+func (b balancer) result(a assignment) Result {
+	r := Result{Teams: make([][]int, len(b.capacity)), Quality: a.quality}
+	for u, t := range a.teamOf {
+		r.Teams[t] = append(r.Teams[t], b.units[u]...)
+	}
+	for _, t := range r.Teams {
+		sort.Ints(t)
+	}
+	return r
+}
+
+// exact evaluates every assignment of units to teams.
+func (b balancer) exact() assignment {
+	best := assignment{quality: -1}
+	teamOf := make([]int, len(b.units))
+	size := make([]int, len(b.capacity))
+
+	var assign func(u int)
+	assign = func(u int) {
+		if u == len(b.units) {
+			if q := b.quality(teamOf); q > best.quality {
+				best = assignment{append([]int(nil), teamOf...), q}
+			}
+			return
+		}
+		tried := make(map[[2]int]bool)
+		for t := range size {
+			// Teams that are empty and have the same capacity are
+			// interchangeable, only the first one is tried.
+			if size[t] == 0 {
+				key := [2]int{0, b.capacity[t]}
+				if tried[key] {
+					continue
+				}
+				tried[key] = true
+			}
+			if size[t]+len(b.units[u]) > b.capacity[t] {
+				continue
+			}
+			teamOf[u] = t
+			size[t] += len(b.units[u])
+			assign(u + 1)
+			size[t] -= len(b.units[u])
+		}
+	}
+	assign(0)
+
+	return best
+}
+
+// localSearch improves a greedy and several random starting assignments by
+// swapping units between teams until no improvement is found.
+func (b balancer) localSearch(rnd *rand.Rand, restarts int) assignment {
+	best := assignment{quality: -1}
+	for r := 0; r <= restarts; r++ {
+		var teamOf []int
+		if r == 0 {
+			teamOf = b.greedy(nil)
+		} else {
+			teamOf = b.greedy(rnd.Perm(len(b.units)))
+		}
+		if teamOf == nil {
+			continue
+		}
+
+		a := b.climb(teamOf)
+		if a.quality > best.quality {
+			best = a
+		}
+	}
+	return best
+}
+
+// greedy assigns the units in the provided order (or by descending skill
+// when order is nil) to the team with the lowest total skill that has room
+// for them. Nil is returned if the units could not be placed.
+func (b balancer) greedy(order []int) []int {
+	if order == nil {
+		order = make([]int, len(b.units))
+		for u := range order {
+			order[u] = u
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return b.unitMu(order[i]) > b.unitMu(order[j])
+		})
+	}
+	// Large units are hardest to place and always go first.
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(b.units[order[i]]) > len(b.units[order[j]])
+	})
+
+	teamOf := make([]int, len(b.units))
+	size := make([]int, len(b.capacity))
+	total := make([]float64, len(b.capacity))
+	for _, u := range order {
+		team := -1
+		for t := range size {
+			if size[t]+len(b.units[u]) > b.capacity[t] {
+				continue
+			}
+			if team == -1 || total[t] < total[team] {
+				team = t
+			}
+		}
+		if team == -1 {
+			return nil
+		}
+		teamOf[u] = team
+		size[team] += len(b.units[u])
+		total[team] += b.unitMu(u)
+	}
+	return teamOf
+}
+
+func (b balancer) unitMu(u int) float64 {
+	var mu float64
+	for _, p := range b.units[u] {
+		mu += b.players[p].Mu()
+	}
+	return mu
+}
+
+// climb applies the best improving swap of two units on different teams
+// until none is left. All teams are always filled to capacity so moving a
+// single unit is never possible.
+func (b balancer) climb(teamOf []int) assignment {
+	size := make([]int, len(b.capacity))
+	for u, t := range teamOf {
+		size[t] += len(b.units[u])
+	}
+	current := assignment{teamOf, b.quality(teamOf)}
+
+	for {
+		best := current
+		var bestSize []int
+		try := func(next []int, nextSize []int) {
+			if q := b.quality(next); q > best.quality {
+				best = assignment{append([]int(nil), next...), q}
+				bestSize = append([]int(nil), nextSize...)
+			}
+		}
+
+		next := append([]int(nil), current.teamOf...)
+		nextSize := append([]int(nil), size...)
+		for u := range b.units {
+			from, n := current.teamOf[u], len(b.units[u])
+			// Swap unit u with a unit v on another team.
+			for v := u + 1; v < len(b.units); v++ {
+				to, m := current.teamOf[v], len(b.units[v])
+				if to == from {
+					continue
+				}
+				if size[from]-n+m > b.capacity[from] || size[to]-m+n > b.capacity[to] {
+					continue
+				}
+				next[u], next[v] = to, from
+				nextSize[from] += m - n
+				nextSize[to] += n - m
+				try(next, nextSize)
+				next[u], next[v] = from, to
+				nextSize[from] -= m - n
+				nextSize[to] -= n - m
+			}
+		}
+
+		if bestSize == nil {
+			return current
+		}
+		current, size = best, bestSize
+	}
+}
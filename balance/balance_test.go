@@ -0,0 +1,136 @@
+package balance
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+func testPlayers(seed int64, n int) []trueskill.Player {
+	rnd := rand.New(rand.NewSource(seed))
+	var players []trueskill.Player
+	for i := 0; i < n; i++ {
+		players = append(players, trueskill.NewPlayer(25+rnd.NormFloat64()*8, 1+rnd.Float64()*5))
+	}
+	return players
+}
+
+func checkPartition(t *testing.T, r Result, n, numTeams int) {
+	if len(r.Teams) != numTeams {
+		t.Fatalf("len(Teams) == %d, want %d", len(r.Teams), numTeams)
+	}
+	seen := make(map[int]bool)
+	for _, team := range r.Teams {
+		if len(team) != n/numTeams && len(team) != n/numTeams+1 {
+			t.Errorf("team %v has unexpected size", team)
+		}
+		for _, p := range team {
+			if seen[p] {
+				t.Errorf("player %d on multiple teams", p)
+			}
+			seen[p] = true
+		}
+	}
+	if len(seen) != n {
+		t.Errorf("%d players assigned, want %d", len(seen), n)
+	}
+}
+
+func TestTeamsExact(t *testing.T) {
+	ts := trueskill.New()
+	players := []trueskill.Player{
+		trueskill.NewPlayer(40, 1),
+		trueskill.NewPlayer(30, 1),
+		trueskill.NewPlayer(20, 1),
+		trueskill.NewPlayer(10, 1),
+	}
+
+	r, err := Teams(ts, players, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPartition(t, r, 4, 2)
+
+	want := [][]int{{0, 3}, {1, 2}}
+	if !reflect.DeepEqual(r.Teams, want) {
+		t.Errorf("Teams == %v, want %v", r.Teams, want)
+	}
+	if q := ts.TeamMatchQuality(r.Players(players)); q != r.Quality {
+		t.Errorf("Quality == %v, want %v", r.Quality, q)
+	}
+}
+
+func TestTeamsParty(t *testing.T) {
+	ts := trueskill.New()
+	players := []trueskill.Player{
+		trueskill.NewPlayer(40, 1),
+		trueskill.NewPlayer(30, 1),
+		trueskill.NewPlayer(20, 1),
+		trueskill.NewPlayer(10, 1),
+	}
+
+	r, err := Teams(ts, players, 2, Party(0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{0, 1}, {2, 3}}
+	if !reflect.DeepEqual(r.Teams, want) {
+		t.Errorf("Teams == %v, want %v", r.Teams, want)
+	}
+
+	if _, err = Teams(ts, players, 2, Party(0, 1, 2)); err == nil {
+		t.Error("Teams() with party larger than a team did not return an error")
+	}
+	if _, err = Teams(ts, players, 2, Party(0, 0)); err == nil {
+		t.Error("Teams() with duplicate party member did not return an error")
+	}
+	if _, err = Teams(ts, players, 1); err == nil {
+		t.Error("Teams() with one team did not return an error")
+	}
+}
+
+func TestTeamsLocalSearch(t *testing.T) {
+	ts := trueskill.New()
+	players := testPlayers(1, 10)
+
+	exact, err := Teams(ts, players, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := Teams(ts, players, 2, ExactLimit(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPartition(t, local, 10, 2)
+	if local.Quality < exact.Quality-1e-3 {
+		t.Errorf("local search quality %.4f, exact %.4f", local.Quality, exact.Quality)
+	}
+}
+
+func TestTeamsLargePool(t *testing.T) {
+	ts := trueskill.New()
+	players := testPlayers(2, 30)
+
+	r, err := Teams(ts, players, 3, Party(0, 1, 2), Party(3, 4), Seed(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPartition(t, r, 30, 3)
+
+	again, _ := Teams(ts, players, 3, Party(0, 1, 2), Party(3, 4), Seed(5))
+	if !reflect.DeepEqual(r, again) {
+		t.Error("Teams() differs between runs with the same seed")
+	}
+
+	// Compare against a random split.
+	naive := Result{Teams: make([][]int, 3)}
+	order := rand.New(rand.NewSource(0)).Perm(30)
+	for i, p := range order {
+		naive.Teams[i%3] = append(naive.Teams[i%3], p)
+	}
+	if q := ts.TeamMatchQuality(naive.Players(players)); r.Quality < q {
+		t.Errorf("Quality == %.4f, random split has %.4f", r.Quality, q)
+	}
+}
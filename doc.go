@@ -29,6 +29,17 @@ Adjust player skills:
 	p1 = newSkills[0]
 	p2 = newSkills[1]
 
+Keep track of who is who by using identified players:
+
+	ts := trueskill.New()
+	alice := ts.NewIdentifiedPlayer("alice")
+	bob := ts.NewIdentifiedPlayer("bob")
+	players, _, err := ts.AdjustIdentifiedSkills([]trueskill.IdentifiedPlayer{alice, bob}, false, time.Now())
+	if err != nil {
+		// Handle error, the ratings were not updated.
+	}
+	fmt.Println(players[0].ID, players[0].GamesPlayed) // alice 1
+
 Check the conservative TrueSkill of a player:
 
 	ts := trueskill.New()
@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
 )
 
 func drawProbability(beta, drawMargin, totalPlayers float64) float64 {
@@ -15,5 +16,15 @@ func drawMargin(beta, drawProb, totalPlayers float64) float64 {
 	// and team B. Considering a match between 3 teams, A, B and C, we would
 	// call drawMargin two times. One time with len(A) + len(B), the other
 	// time with len(B) + len(C).
-	return -math.Sqrt((totalPlayers)*beta*beta) * gaussian.NormPpf((1-drawProb)/2)
+	//
+	// The margin is sqrt(totalPlayers)*beta*NormPpf((1+drawProb)/2), written
+	// with the inverse error function to keep the precision of tiny draw
+	// probabilities.
+	return math.Sqrt(totalPlayers) * beta * math.Sqrt2 * mathextra.InvErf(drawProb)
+}
+
+// DrawMargin returns the margin within which the performance difference of
+// two teams, with totalPlayers players between them, is considered a draw.
+func (ts Config) DrawMargin(totalPlayers int) float64 {
+	return drawMargin(ts.beta, ts.drawProbability, float64(totalPlayers))
 }
@@ -0,0 +1,28 @@
+package trueskill
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDrawMargin(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    float64 // Margin for two players with the default beta
+	}{
+		{0, 0},
+		{1e-10, math.Sqrt(2) * DefaultBeta * math.Sqrt(math.Pi/2) * 1e-12}, // Linear for tiny probabilities
+		{10, 0.74046658745214738},
+		{100, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		opt, err := DrawProbability(tt.percent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := New(opt).DrawMargin(2)
+		if got != tt.want && math.Abs(got-tt.want) > 1e-12*tt.want {
+			t.Errorf("DrawMargin(2) with %g%% draws == %.17g, want %.17g", tt.percent, got, tt.want)
+		}
+	}
+}
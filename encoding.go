@@ -0,0 +1,643 @@
+package trueskill
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/internal/textfield"
+)
+
+// Versions of the binary encodings, stored as the first byte.
+const (
+	playerBinaryVersion           = 1
+	identifiedPlayerBinaryVersion = 1
+	configBinaryVersion           = 1
+)
+
+var (
+	errBinaryLength  = errors.New("invalid binary length")
+	errBinaryVersion = errors.New("unsupported binary version")
+)
+
+// MarshalText encodes the player in the precision form of its skill as
+// "precisionMean=<float> precision=<float>". The encoding is lossless.
+func (p Player) MarshalText() ([]byte, error) {
+	var w textfield.Writer
+	writeSkill(&w, p)
+	return w.Bytes(), nil
+}
+
+// UnmarshalText decodes a player encoded by MarshalText.
+func (p *Player) UnmarshalText(text []byte) error {
+	fields, err := textfield.Parse(text)
+	if err != nil {
+		return err
+	}
+	v, err := takeSkill(fields)
+	if err != nil {
+		return err
+	}
+	if err = fields.Unknown(); err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// writeSkill writes the skill of the player in the precision form, which
+// parses back to the exact same gaussian.
+func writeSkill(w *textfield.Writer, p Player) {
+	w.Float("precisionMean", p.PrecisionMean)
+	w.Float("precision", p.Precision)
+}
+
+// takeSkill consumes the fields written by writeSkill.
+func takeSkill(fields textfield.Fields) (Player, error) {
+	precisionMean, err := fields.Float("precisionMean")
+	if err != nil {
+		return Player{}, err
+	}
+	precision, err := fields.Float("precision")
+	if err != nil {
+		return Player{}, err
+	}
+	return Player{Gaussian: gaussian.NewFromPrecision(precisionMean, precision)}, nil
+}
+
+// playerJSON is the JSON form of the skill of a player.
+type playerJSON struct {
+	PrecisionMean *float64 `json:"precisionMean,omitempty"`
+	Precision     *float64 `json:"precision,omitempty"`
+}
+
+func newPlayerJSON(p Player) playerJSON {
+	precisionMean, precision := p.PrecisionMean, p.Precision
+	return playerJSON{PrecisionMean: &precisionMean, Precision: &precision}
+}
+
+func (v playerJSON) player() (Player, error) {
+	if v.PrecisionMean == nil {
+		return Player{}, errors.New("missing field precisionMean")
+	}
+	if v.Precision == nil {
+		return Player{}, errors.New("missing field precision")
+	}
+	return Player{Gaussian: gaussian.NewFromPrecision(*v.PrecisionMean, *v.Precision)}, nil
+}
+
+// MarshalJSON encodes the player as a JSON object with the fields
+// precisionMean and precision. The encoding is lossless and the zero value
+// is encoded as zeros.
+func (p Player) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newPlayerJSON(p))
+}
+
+// UnmarshalJSON decodes a player encoded by MarshalJSON.
+func (p *Player) UnmarshalJSON(data []byte) error {
+	var v playerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	player, err := v.player()
+	if err != nil {
+		return err
+	}
+	*p = player
+	return nil
+}
+
+// MarshalBinary encodes the player into a compact, versioned binary form.
+// Like the text and JSON encodings, the binary encoding stores the
+// underlying gaussian and is lossless.
+func (p Player) MarshalBinary() ([]byte, error) {
+	g, err := p.Gaussian.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{playerBinaryVersion}, g...), nil
+}
+
+// UnmarshalBinary decodes a player encoded by MarshalBinary.
+func (p *Player) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errBinaryLength
+	}
+	if data[0] != playerBinaryVersion {
+		return errBinaryVersion
+	}
+	var g gaussian.Gaussian
+	if err := g.UnmarshalBinary(data[1:]); err != nil {
+		return err
+	}
+	*p = Player{Gaussian: g}
+	return nil
+}
+
+// Value implements driver.Valuer, the player is stored in its text form.
+func (p Player) Value() (driver.Value, error) {
+	return textValue(p)
+}
+
+// Scan implements sql.Scanner for values stored by Value.
+func (p *Player) Scan(src interface{}) error {
+	return scanText(p, src)
+}
+
+// MarshalText encodes the identified player as space separated key=value
+// pairs, the id is quoted.
+func (p IdentifiedPlayer) MarshalText() ([]byte, error) {
+	var w textfield.Writer
+	w.Field("id", strconv.Quote(p.ID))
+	writeSkill(&w, p.Player)
+	w.Field("games", strconv.Itoa(p.GamesPlayed))
+	if !p.LastPlayed.IsZero() {
+		w.Field("lastPlayed", p.LastPlayed.Format(time.RFC3339Nano))
+	}
+	w.Field("provisional", strconv.FormatBool(p.Provisional))
+	return w.Bytes(), nil
+}
+
+// UnmarshalText decodes an identified player encoded by MarshalText.
+func (p *IdentifiedPlayer) UnmarshalText(text []byte) error {
+	fields, err := textfield.Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var v IdentifiedPlayer
+	v.ID, _ = fields.Take("id")
+	if v.Player, err = takeSkill(fields); err != nil {
+		return err
+	}
+	if s, ok := fields.Take("games"); ok {
+		if v.GamesPlayed, err = strconv.Atoi(s); err != nil {
+			return fmt.Errorf("invalid games: %v", err)
+		}
+	}
+	if s, ok := fields.Take("lastPlayed"); ok {
+		if v.LastPlayed, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return fmt.Errorf("invalid lastPlayed: %v", err)
+		}
+	}
+	if s, ok := fields.Take("provisional"); ok {
+		if v.Provisional, err = strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("invalid provisional: %v", err)
+		}
+	}
+	if err = fields.Unknown(); err != nil {
+		return err
+	}
+
+	*p = v
+	return nil
+}
+
+type identifiedPlayerJSON struct {
+	ID string `json:"id"`
+	playerJSON
+	GamesPlayed int        `json:"gamesPlayed"`
+	LastPlayed  *time.Time `json:"lastPlayed,omitempty"`
+	Provisional bool       `json:"provisional"`
+}
+
+// MarshalJSON encodes the identified player as a JSON object.
+func (p IdentifiedPlayer) MarshalJSON() ([]byte, error) {
+	v := identifiedPlayerJSON{
+		ID:          p.ID,
+		playerJSON:  newPlayerJSON(p.Player),
+		GamesPlayed: p.GamesPlayed,
+		Provisional: p.Provisional,
+	}
+	if !p.LastPlayed.IsZero() {
+		v.LastPlayed = &p.LastPlayed
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes an identified player encoded by MarshalJSON.
+func (p *IdentifiedPlayer) UnmarshalJSON(data []byte) error {
+	var v identifiedPlayerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	player, err := v.player()
+	if err != nil {
+		return err
+	}
+	*p = IdentifiedPlayer{
+		Player:      player,
+		ID:          v.ID,
+		GamesPlayed: v.GamesPlayed,
+		Provisional: v.Provisional,
+	}
+	if v.LastPlayed != nil {
+		p.LastPlayed = *v.LastPlayed
+	}
+	return nil
+}
+
+// MarshalBinary encodes the identified player into a compact, versioned
+// binary form. The skill is stored losslessly, see Player.MarshalBinary.
+func (p IdentifiedPlayer) MarshalBinary() ([]byte, error) {
+	skill, err := p.Player.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var lastPlayed []byte
+	if !p.LastPlayed.IsZero() {
+		if lastPlayed, err = p.LastPlayed.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+
+	var w binaryWriter
+	w.byte(identifiedPlayerBinaryVersion)
+	w.bytes([]byte(p.ID))
+	w.bytes(skill)
+	w.uvarint(uint64(p.GamesPlayed))
+	w.bytes(lastPlayed)
+	w.bool(p.Provisional)
+	return w.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an identified player encoded by MarshalBinary.
+func (p *IdentifiedPlayer) UnmarshalBinary(data []byte) error {
+	r := binaryReader{data: data}
+	if r.byte() != identifiedPlayerBinaryVersion {
+		if r.err != nil {
+			return r.err
+		}
+		return errBinaryVersion
+	}
+	id := r.bytes()
+	skill := r.bytes()
+	games := r.uvarint()
+	lastPlayed := r.bytes()
+	provisional := r.bool()
+	if err := r.done(); err != nil {
+		return err
+	}
+
+	var v IdentifiedPlayer
+	if err := v.Player.UnmarshalBinary(skill); err != nil {
+		return err
+	}
+	if len(lastPlayed) > 0 {
+		if err := v.LastPlayed.UnmarshalBinary(lastPlayed); err != nil {
+			return err
+		}
+	}
+	v.ID = string(id)
+	v.GamesPlayed = int(games)
+	v.Provisional = provisional
+
+	*p = v
+	return nil
+}
+
+// Value implements driver.Valuer, the identified player is stored in its
+// text form.
+func (p IdentifiedPlayer) Value() (driver.Value, error) {
+	return textValue(p)
+}
+
+// Scan implements sql.Scanner for values stored by Value.
+func (p *IdentifiedPlayer) Scan(src interface{}) error {
+	return scanText(p, src)
+}
+
+// MarshalText encodes all configuration options as space separated key=value
+// pairs. The draw probability is encoded as a value between zero and one,
+// not as the percentage given to DrawProbability, so that it decodes to the
+// exact same value.
+func (ts Config) MarshalText() ([]byte, error) {
+	var w textfield.Writer
+	w.Float("mu", ts.mu)
+	w.Float("sigma", ts.sigma)
+	w.Float("beta", ts.beta)
+	w.Float("tau", ts.tau)
+	w.Float("drawProbability", ts.drawProbability)
+	w.Field("provisional", strconv.Itoa(ts.provisionalGames))
+	return w.Bytes(), nil
+}
+
+// UnmarshalText decodes a configuration encoded by MarshalText. Options
+// missing from the text keep their default value. The decoded configuration
+// is validated, see Config.Validate.
+func (ts *Config) UnmarshalText(text []byte) error {
+	fields, err := textfield.Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var opts []Option
+	for _, f := range []struct {
+		key    string
+		option func(float64) Option
+	}{
+		{"mu", Mu},
+		{"sigma", Sigma},
+		{"beta", Beta},
+		{"tau", Tau},
+	} {
+		if !fields.Has(f.key) {
+			continue
+		}
+		v, err := fields.Float(f.key)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, f.option(v))
+	}
+	var drawProbability *float64
+	if fields.Has("drawProbability") {
+		v, err := fields.Float("drawProbability")
+		if err != nil {
+			return err
+		}
+		drawProbability = &v
+	}
+	if s, ok := fields.Take("provisional"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid provisional: %v", err)
+		}
+		opts = append(opts, ProvisionalGames(n))
+	}
+	if err = fields.Unknown(); err != nil {
+		return err
+	}
+
+	c, err := newDecodedConfig(opts, drawProbability)
+	if err != nil {
+		return err
+	}
+
+	*ts = c
+	return nil
+}
+
+type configJSON struct {
+	Mu               *float64 `json:"mu,omitempty"`
+	Sigma            *float64 `json:"sigma,omitempty"`
+	Beta             *float64 `json:"beta,omitempty"`
+	Tau              *float64 `json:"tau,omitempty"`
+	DrawProbability  *float64 `json:"drawProbability,omitempty"`
+	ProvisionalGames *int     `json:"provisionalGames,omitempty"`
+}
+
+// MarshalJSON encodes all configuration options as a JSON object. The draw
+// probability is encoded as a value between zero and one, see
+// Config.MarshalText.
+func (ts Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(configJSON{
+		Mu:               &ts.mu,
+		Sigma:            &ts.sigma,
+		Beta:             &ts.beta,
+		Tau:              &ts.tau,
+		DrawProbability:  &ts.drawProbability,
+		ProvisionalGames: &ts.provisionalGames,
+	})
+}
+
+// UnmarshalJSON decodes a configuration encoded by MarshalJSON. Options
+// missing from the object keep their default value. The decoded
+// configuration is validated, see Config.Validate.
+func (ts *Config) UnmarshalJSON(data []byte) error {
+	var v configJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	var opts []Option
+	if v.Mu != nil {
+		opts = append(opts, Mu(*v.Mu))
+	}
+	if v.Sigma != nil {
+		opts = append(opts, Sigma(*v.Sigma))
+	}
+	if v.Beta != nil {
+		opts = append(opts, Beta(*v.Beta))
+	}
+	if v.Tau != nil {
+		opts = append(opts, Tau(*v.Tau))
+	}
+	if v.ProvisionalGames != nil {
+		opts = append(opts, ProvisionalGames(*v.ProvisionalGames))
+	}
+
+	c, err := newDecodedConfig(opts, v.DrawProbability)
+	if err != nil {
+		return err
+	}
+
+	*ts = c
+	return nil
+}
+
+// newDecodedConfig works like NewChecked for a decoded configuration. The
+// draw probability, if any, is a value between zero and one and is set as is,
+// unlike DrawProbability it is not scaled from a percentage, which would not
+// decode to the exact value that was encoded.
+func newDecodedConfig(opts []Option, drawProbability *float64) (Config, error) {
+	c := New(opts...)
+	if drawProbability != nil {
+		c.drawProbability = *drawProbability
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// MarshalBinary encodes all configuration options into a compact, versioned
+// binary form.
+func (ts Config) MarshalBinary() ([]byte, error) {
+	var w binaryWriter
+	w.byte(configBinaryVersion)
+	w.float(ts.mu)
+	w.float(ts.sigma)
+	w.float(ts.beta)
+	w.float(ts.tau)
+	w.float(ts.drawProbability)
+	w.uvarint(uint64(ts.provisionalGames))
+	return w.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a configuration encoded by MarshalBinary. The
+// decoded configuration is validated, see Config.Validate.
+func (ts *Config) UnmarshalBinary(data []byte) error {
+	r := binaryReader{data: data}
+	if r.byte() != configBinaryVersion {
+		if r.err != nil {
+			return r.err
+		}
+		return errBinaryVersion
+	}
+	c := Config{
+		mu:               r.float(),
+		sigma:            r.float(),
+		beta:             r.float(),
+		tau:              r.float(),
+		drawProbability:  r.float(),
+		provisionalGames: int(r.uvarint()),
+	}
+	if err := r.done(); err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	*ts = c
+	return nil
+}
+
+// Value implements driver.Valuer, the configuration is stored in its text
+// form.
+func (ts Config) Value() (driver.Value, error) {
+	return textValue(ts)
+}
+
+// Scan implements sql.Scanner for values stored by Value.
+func (ts *Config) Scan(src interface{}) error {
+	return scanText(ts, src)
+}
+
+type textMarshaler interface {
+	MarshalText() ([]byte, error)
+}
+
+type textUnmarshaler interface {
+	UnmarshalText(text []byte) error
+}
+
+func textValue(m textMarshaler) (driver.Value, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+func scanText(u textUnmarshaler, src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return u.UnmarshalText([]byte(src))
+	case []byte:
+		return u.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T", src)
+	}
+}
+
+// binaryWriter writes the fields of a binary encoding.
+type binaryWriter struct {
+	bytes.Buffer
+}
+
+func (w *binaryWriter) byte(b byte) {
+	w.WriteByte(b)
+}
+
+func (w *binaryWriter) bool(b bool) {
+	if b {
+		w.byte(1)
+	} else {
+		w.byte(0)
+	}
+}
+
+func (w *binaryWriter) uvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	w.Write(buf[:n])
+}
+
+func (w *binaryWriter) float(f float64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(f))
+	w.Write(buf[:])
+}
+
+func (w *binaryWriter) bytes(b []byte) {
+	w.uvarint(uint64(len(b)))
+	w.Write(b)
+}
+
+// binaryReader reads the fields written by binaryWriter. The first error is
+// kept and all subsequent reads return zero values.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = errBinaryLength
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *binaryReader) byte() byte {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *binaryReader) bool() bool {
+	return r.byte() == 1
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errBinaryLength
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binaryReader) float() float64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b))
+}
+
+func (r *binaryReader) bytes() []byte {
+	n := r.uvarint()
+	if n > uint64(len(r.data)) {
+		r.err = errBinaryLength
+		return nil
+	}
+	return r.next(int(n))
+}
+
+func (r *binaryReader) done() error {
+	if r.err == nil && len(r.data) > 0 {
+		r.err = errBinaryLength
+	}
+	return r.err
+}
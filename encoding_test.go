@@ -0,0 +1,190 @@
+package trueskill
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type marshaler interface {
+	encoding.TextMarshaler
+	encoding.BinaryMarshaler
+	json.Marshaler
+}
+
+type unmarshaler interface {
+	encoding.TextUnmarshaler
+	encoding.BinaryUnmarshaler
+	json.Unmarshaler
+}
+
+func testMarshalRoundTrip(t *testing.T, in marshaler, newOut func() unmarshaler) {
+	text, err := in.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := newOut()
+	if err = out.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText(%q) error: %v", text, err)
+	}
+	if got := reflect.ValueOf(out).Elem().Interface(); !reflect.DeepEqual(got, in) {
+		t.Errorf("UnmarshalText(%q) == %#v, want %#v", text, got, in)
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = newOut()
+	if err = json.Unmarshal(data, out); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", data, err)
+	}
+	if got := reflect.ValueOf(out).Elem().Interface(); !reflect.DeepEqual(got, in) {
+		t.Errorf("json.Unmarshal(%s) == %#v, want %#v", data, got, in)
+	}
+
+	bin, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = newOut()
+	if err = out.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("UnmarshalBinary(%x) error: %v", bin, err)
+	}
+	if got := reflect.ValueOf(out).Elem().Interface(); !reflect.DeepEqual(got, in) {
+		t.Errorf("UnmarshalBinary(%x) == %#v, want %#v", bin, got, in)
+	}
+	for i := range bin {
+		if err = newOut().UnmarshalBinary(bin[:i]); err == nil {
+			t.Errorf("UnmarshalBinary(%x) of truncated data did not return an error", bin[:i])
+		}
+	}
+}
+
+// ratedPlayers returns the skills of players after rating matches, unlike
+// hand picked skills their mean and standard deviation are not short
+// decimals.
+func ratedPlayers() []Player {
+	ts := New()
+	var players []Player
+	for i := 0; i < 20; i++ {
+		match := []Player{NewPlayer(25+float64(i), 8-0.3*float64(i)), ts.NewPlayer(), NewPlayer(20, 3)}
+		newSkills, _ := ts.AdjustSkills(match, i%4 == 0)
+		players = append(players, newSkills...)
+	}
+	return players
+}
+
+func TestPlayerMarshal(t *testing.T) {
+	for _, p := range append(ratedPlayers(), Player{}) {
+		testMarshalRoundTrip(t, p, func() unmarshaler { return new(Player) })
+
+		v, err := p.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var scanned Player
+		if err = scanned.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if scanned != p {
+			t.Errorf("Scan(%q) == %v, want %v", v, scanned, p)
+		}
+	}
+
+	text, _ := NewPlayer(25, 0.5).MarshalText()
+	if want := "precisionMean=100 precision=4"; string(text) != want {
+		t.Errorf("MarshalText() == %q, want %q", text, want)
+	}
+	data, err := json.Marshal(Player{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"precisionMean":0,"precision":0}`; string(data) != want {
+		t.Errorf("json.Marshal(Player{}) == %s, want %s", data, want)
+	}
+}
+
+func TestPlayerUnmarshalErrors(t *testing.T) {
+	var p Player
+	for _, text := range []string{"", "mu=25 sigma=0.5", "precision=1", "precisionMean=1 precision=1 mu=2 sigma=3", "precisionMean=1 precision=1 precision=2"} {
+		if err := p.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) did not return an error", text)
+		}
+	}
+	for _, data := range []string{`{}`, `{"mu":25,"sigma":0.5}`, `{"precision":1}`} {
+		if err := json.Unmarshal([]byte(data), &p); err == nil {
+			t.Errorf("json.Unmarshal(%s) did not return an error", data)
+		}
+	}
+}
+
+func TestIdentifiedPlayerMarshal(t *testing.T) {
+	for _, skill := range ratedPlayers()[:6] {
+		p := NewIdentifiedPlayer(`alice "the great"`, skill)
+		p.GamesPlayed = 3
+		testMarshalRoundTrip(t, p, func() unmarshaler { return new(IdentifiedPlayer) })
+
+		p.LastPlayed = time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+		p.Provisional = false
+		testMarshalRoundTrip(t, p, func() unmarshaler { return new(IdentifiedPlayer) })
+
+		v, err := p.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var scanned IdentifiedPlayer
+		if err = scanned.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if scanned != p {
+			t.Errorf("Scan(%q) == %v, want %v", v, scanned, p)
+		}
+	}
+}
+
+func TestConfigMarshal(t *testing.T) {
+	drawProbability, err := DrawProbability(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := New(Mu(200), Sigma(64), Beta(32), Tau(0.5), drawProbability, ProvisionalGames(5))
+	testMarshalRoundTrip(t, ts, func() unmarshaler { return new(Config) })
+	testMarshalRoundTrip(t, New(), func() unmarshaler { return new(Config) })
+
+	var partial Config
+	if err = partial.UnmarshalText([]byte("mu=200")); err != nil {
+		t.Fatal(err)
+	}
+	if want := New(Mu(200)); partial != want {
+		t.Errorf("UnmarshalText(%q) == %v, want %v", "mu=200", partial, want)
+	}
+
+	for _, text := range []string{"drawProbability=1.01", "mu=x", "foo=1", "mu=1 mu=2", "mu"} {
+		if err = partial.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) did not return an error", text)
+		}
+	}
+
+	// Draw probabilities that are not exact in binary must decode to the
+	// exact same value.
+	for i := 1; i < 10000; i++ {
+		c := New()
+		c.drawProbability = float64(i) / 10000
+		testMarshalRoundTrip(t, c, func() unmarshaler { return new(Config) })
+	}
+
+	v, err := ts.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned Config
+	if err = scanned.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if scanned != ts {
+		t.Errorf("Scan(%q) == %v, want %v", v, scanned, ts)
+	}
+}
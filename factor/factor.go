@@ -1,5 +1,28 @@
 package factor
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+// Kinds of the factors created by GaussianFactors and Graph.
+const (
+	KindPrior       = "prior"
+	KindLikelihood  = "likelihood"
+	KindWeightedSum = "weightedSum"
+	KindGreaterThan = "greaterThan"
+	KindWithin      = "within"
+	KindCustom      = "custom"
+)
+
+var (
+	errImproperCavity      = errors.New("truncation of an improper gaussian")
+	errTruncationNotProper = errors.New("truncation produced a gaussian that is not proper")
+)
+
 // Factor is a factor capable of updating the factor graph.
 type Factor struct {
 	UpdateMessage    func(i int) float64
@@ -7,6 +30,12 @@ type Factor struct {
 	NumMessages      int
 	ResetMarginals   func()
 	SendMessage      func(i int) float64
+
+	Name      string                        // Optional name, set by the user of the factor
+	Kind      string                        // Kind of factor, e.g. KindPrior
+	Variables []int                         // Variable index of every message
+	Message   func(i int) gaussian.Gaussian // Current message to Variables[i]
+	Err       func() error                  // Error of the last message update, nil if the factor cannot fail
 }
 
 // List is a list of all factors, used to get the log normalization for the
@@ -21,6 +50,30 @@ func (fl *List) Add(f Factor) Factor {
 	return f
 }
 
+// Factors returns the factors in the order they were added.
+func (fl List) Factors() []Factor {
+	return fl.list
+}
+
+// Err returns the error of the last message update of the first factor
+// whose update failed, nil if the last update of every factor succeeded. A
+// factor that fails, e.g. the truncation of an improper gaussian, keeps its
+// previous message, so the marginals are not the result of the inference.
+func (fl List) Err() error {
+	for _, f := range fl.list {
+		if f.Err == nil {
+			continue
+		}
+		if err := f.Err(); err != nil {
+			if f.Name != "" {
+				return fmt.Errorf("%s: %v", f.Name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // LogNormalization returns the log normalization of all factors in the factor
 // graph.
 func (fl List) LogNormalization() float64 {
@@ -42,3 +95,20 @@ func (fl List) LogNormalization() float64 {
 
 	return sumLogZ + sumLogS
 }
+
+// Schedule derives a schedule for the factors with schedule.Derive. The
+// messages of the greater-than, within and custom factors are treated as
+// approximate.
+func Schedule(factors []Factor, maxDelta float64) schedule.Runner {
+	var facs []schedule.Factor
+	for _, f := range factors {
+		facs = append(facs, schedule.Factor{
+			Label:       f.Name,
+			Variables:   f.Variables,
+			Update:      f.UpdateMessage,
+			Approximate: f.Kind == KindGreaterThan || f.Kind == KindWithin || f.Kind == KindCustom,
+		})
+	}
+
+	return schedule.Derive(facs, maxDelta)
+}
@@ -31,6 +31,13 @@ func sendMessageHelper(msgIdx, varIdx int, msgBag, varBag *collection.Distributi
 	return gaussian.LogProdNorm(mar, msg)
 }
 
+// messageFunc returns a function that returns the message at msgIdx[i].
+func (gf GaussianFactors) messageFunc(msgIdx ...int) func(i int) gaussian.Gaussian {
+	return func(i int) gaussian.Gaussian {
+		return gf.msgBag.Get(msgIdx[i])
+	}
+}
+
 // GaussianPrior calculates the prior for the factor graph.
 func (gf GaussianFactors) GaussianPrior(mu, sigmaSquared float64, varIdx int,
 	varBag *collection.DistributionBag) Factor {
@@ -66,6 +73,9 @@ func (gf GaussianFactors) GaussianPrior(mu, sigmaSquared float64, varIdx int,
 		NumMessages:      1,
 		ResetMarginals:   func() { varBag.PutPriorAt(varIdx) },
 		SendMessage:      sendMessage,
+		Kind:             KindPrior,
+		Variables:        []int{varIdx},
+		Message:          gf.messageFunc(msgIdx),
 	}
 }
 
@@ -82,10 +92,10 @@ func (gf GaussianFactors) GaussianLikeliehood(betaSquared float64, varIdx1, varI
 		mar1 := bag1.Get(v1)
 		mar2 := bag2.Get(v2)
 
-		a := prec / (prec + mar2.Precision - msg2.Precision)
-		newMsg := gaussian.NewFromPrecision(a*(mar2.PrecisionMean-msg2.PrecisionMean),
-			a*(mar2.Precision-msg2.Precision))
-		oldMarginalWithoutMsg := mar1.Div(msg1)
+		cavity := mar2.Div(msg2).ClampPrecision()
+		a := prec / (prec + cavity.Precision)
+		newMsg := gaussian.NewFromPrecision(a*cavity.PrecisionMean, a*cavity.Precision)
+		oldMarginalWithoutMsg := mar1.Div(msg1).ClampPrecision()
 		newMarginal := oldMarginalWithoutMsg.Mul(newMsg)
 
 		gf.msgBag.Put(m1, newMsg)
@@ -129,6 +139,9 @@ func (gf GaussianFactors) GaussianLikeliehood(betaSquared float64, varIdx1, varI
 		NumMessages:      2,
 		ResetMarginals:   resetMarginals,
 		SendMessage:      sendMessage,
+		Kind:             KindLikelihood,
+		Variables:        []int{varIdx1, varIdx2},
+		Message:          gf.messageFunc(msgIdx1, msgIdx2),
 	}
 }
 
@@ -136,120 +149,194 @@ func (gf GaussianFactors) GaussianLikeliehood(betaSquared float64, varIdx1, varI
 func (gf GaussianFactors) GaussianWeightedSum(a1, a2 float64, varIdx0, varIdx1, varIdx2 int,
 	varBag0, varBag1, varBag2 *collection.DistributionBag) Factor {
 
-	msgIdx0 := gf.msgBag.NextIndex()
-	msgIdx1 := gf.msgBag.NextIndex()
-	msgIdx2 := gf.msgBag.NextIndex()
-	weights0 := []float64{a1, a2}
-	weights0Squared := []float64{weights0[0] * weights0[0], weights0[1] * weights0[1]}
-	weights1 := []float64{-a2 / a1, 1.0 / a1}
-	weights1Squared := []float64{weights1[0] * weights1[0], weights1[1] * weights1[1]}
-	weights2 := []float64{-a1 / a2, 1.0 / a2}
-	weights2Squared := []float64{weights2[0] * weights2[0], weights2[1] * weights2[1]}
-
-	updateHelper := func(w, wS []float64, m1, m2, m3, v1, v2, v3 int, bag1, bag2, bag3 *collection.DistributionBag) float64 {
-		d0 := bag2.Get(v2).Div(gf.msgBag.Get(m2))
-		d1 := bag3.Get(v3).Div(gf.msgBag.Get(m3))
-		msg1 := gf.msgBag.Get(m1)
-		mar1 := bag1.Get(v1)
-		denom := wS[0]*d1.Precision + wS[1]*d0.Precision
-		newPrecisionMean := (w[0]*d1.Precision*d0.PrecisionMean + w[1]*d0.Precision*d1.PrecisionMean) / denom
-		newPrecision := d0.Precision * d1.Precision / denom
-		newMsg := gaussian.NewFromPrecision(newPrecisionMean, newPrecision)
-		oldMarginalWithoutMsg := mar1.Div(msg1)
-		newMarginal := oldMarginalWithoutMsg.Mul(newMsg)
+	return gf.gaussianWeightedSumN([]float64{a1, a2}, []int{varIdx0, varIdx1, varIdx2},
+		[]*collection.DistributionBag{varBag0, varBag1, varBag2})
+}
 
-		gf.msgBag.Put(m1, newMsg)
-		bag1.Put(v1, newMarginal)
+// GaussianWeightedSumN calculates the weighted sum of any number of terms for
+// the factor graph, the variable at sumIdx is the sum of the variables at
+// termIdx multiplied by their weights. Message 0 is sent to the sum and
+// message i to termIdx[i-1].
+func (gf GaussianFactors) GaussianWeightedSumN(weights []float64, sumIdx int, termIdx []int,
+	varBag *collection.DistributionBag) Factor {
 
-		return newMarginal.Sub(mar1)
+	if len(weights) != len(termIdx) {
+		panic("Number of weights and terms differ")
 	}
+
+	varIdx := append([]int{sumIdx}, termIdx...)
+	varBags := make([]*collection.DistributionBag, len(varIdx))
+	for i := range varBags {
+		varBags[i] = varBag
+	}
+
+	return gf.gaussianWeightedSumN(weights, varIdx, varBags)
+}
+
+// weightedSumDirections returns the weights of the other variables in every
+// update direction of a weighted sum, solving the sum for each variable.
+// Variable 0 is the sum.
+func weightedSumDirections(weights []float64) [][]float64 {
+	n := len(weights) + 1
+	directions := make([][]float64, n)
+
+	directions[0] = append([]float64{0}, weights...)
+	for i := 1; i < n; i++ {
+		w := make([]float64, n)
+		a := weights[i-1]
+		if a != 0 {
+			w[0] = 1 / a
+			for j := 1; j < n; j++ {
+				if j != i {
+					w[j] = -weights[j-1] / a
+				}
+			}
+		}
+		directions[i] = w
+	}
+
+	return directions
+}
+
+// weightedSumMessage returns the message to a variable that is the weighted
+// sum of the cavities (the marginals divided by the messages from the
+// factor). The message is uniform if any weighted cavity is uniform or all
+// weights are zero.
+func weightedSumMessage(weights []float64, cavities []gaussian.Gaussian) gaussian.Gaussian {
+	var mean, variance float64
+	for j, w := range weights {
+		if w == 0 {
+			continue
+		}
+		d := cavities[j]
+		if d.Precision == 0 {
+			return gaussian.NewFromPrecision(0, 0)
+		}
+		mean += w * d.PrecisionMean / d.Precision
+		variance += w * w / d.Precision
+	}
+	if variance == 0 {
+		return gaussian.NewFromPrecision(0, 0)
+	}
+
+	return gaussian.NewFromPrecision(mean/variance, 1/variance)
+}
+
+func (gf GaussianFactors) gaussianWeightedSumN(weights []float64, varIdx []int, varBags []*collection.DistributionBag) Factor {
+	n := len(varIdx)
+	msgIdx := make([]int, n)
+	for i := range msgIdx {
+		msgIdx[i] = gf.msgBag.NextIndex()
+	}
+	directions := weightedSumDirections(weights)
+
 	updateMessage := func(i int) float64 {
-		switch i {
-		case 0:
-			return updateHelper(weights0, weights0Squared, msgIdx0, msgIdx1, msgIdx2, varIdx0, varIdx1, varIdx2,
-				varBag0, varBag1, varBag2)
-		case 1:
-			return updateHelper(weights1, weights1Squared, msgIdx1, msgIdx2, msgIdx0, varIdx1, varIdx2, varIdx0,
-				varBag1, varBag2, varBag0)
-		case 2:
-			return updateHelper(weights2, weights2Squared, msgIdx2, msgIdx1, msgIdx0, varIdx2, varIdx1, varIdx0,
-				varBag2, varBag1, varBag0)
-		default:
+		if i < 0 || i >= n {
 			panic("Index out of range.")
 		}
+
+		cavities := make([]gaussian.Gaussian, n)
+		for j := range cavities {
+			if j != i {
+				cavities[j] = varBags[j].Get(varIdx[j]).Div(gf.msgBag.Get(msgIdx[j])).ClampPrecision()
+			}
+		}
+		newMsg := weightedSumMessage(directions[i], cavities)
+		mar := varBags[i].Get(varIdx[i])
+		newMarginal := mar.Div(gf.msgBag.Get(msgIdx[i])).ClampPrecision().Mul(newMsg)
+
+		gf.msgBag.Put(msgIdx[i], newMsg)
+		varBags[i].Put(varIdx[i], newMarginal)
+
+		return newMarginal.Sub(mar)
 	}
 	logNormalization := func() float64 {
-		ratio1 := gaussian.LogRatioNorm(varBag1.Get(varIdx1), gf.msgBag.Get(msgIdx1))
-		ratio2 := gaussian.LogRatioNorm(varBag2.Get(varIdx2), gf.msgBag.Get(msgIdx2))
-		return ratio1 + ratio2
+		var logNorm float64
+		for j := 1; j < n; j++ {
+			logNorm += gaussian.LogRatioNorm(varBags[j].Get(varIdx[j]), gf.msgBag.Get(msgIdx[j]))
+		}
+		return logNorm
 	}
 	resetMarginals := func() {
-		varBag0.PutPriorAt(varIdx0)
-		varBag1.PutPriorAt(varIdx1)
-		varBag2.PutPriorAt(varIdx2)
+		for j := range varIdx {
+			varBags[j].PutPriorAt(varIdx[j])
+		}
 	}
 	sendMessage := func(i int) float64 {
-		switch i {
-		case 0:
-			return sendMessageHelper(msgIdx0, varIdx0, gf.msgBag, varBag0)
-		case 1:
-			return sendMessageHelper(msgIdx1, varIdx1, gf.msgBag, varBag1)
-		case 2:
-			return sendMessageHelper(msgIdx2, varIdx2, gf.msgBag, varBag2)
-		default:
+		if i < 0 || i >= n {
 			panic("Index out of range")
 		}
+
+		return sendMessageHelper(msgIdx[i], varIdx[i], gf.msgBag, varBags[i])
 	}
 
 	return Factor{
 		UpdateMessage:    updateMessage,
 		LogNormalization: logNormalization,
-		NumMessages:      3,
+		NumMessages:      n,
 		ResetMarginals:   resetMarginals,
 		SendMessage:      sendMessage,
+		Kind:             KindWeightedSum,
+		Variables:        varIdx,
+		Message:          gf.messageFunc(msgIdx...),
 	}
 }
 
-func gaussianGreaterThanOrWithinUpdateMessage(epsilon float64, msgIdx, varIdx int,
-	msgBag, varBag *collection.DistributionBag, vFunc, wFunc func(t, epsilon float64) float64) float64 {
+// gaussianGreaterThanOrWithinUpdateMessage truncates the variable shifted by
+// offset, the marginal of the shifted variable is computed from the shifted
+// cavity and shifted back. The message is kept and an error is returned if
+// the truncation is undefined or not proper.
+func gaussianGreaterThanOrWithinUpdateMessage(epsilon, offset float64, msgIdx, varIdx int,
+	msgBag, varBag *collection.DistributionBag, vFunc, wFunc func(t, epsilon float64) float64) (float64, error) {
 	oldMarginal := varBag.Get(varIdx)
 	oldMsg := msgBag.Get(msgIdx)
-	msgFromVar := oldMarginal.Div(oldMsg)
+	msgFromVar := oldMarginal.Div(oldMsg).ClampPrecision()
 	c := msgFromVar.Precision
-	d := msgFromVar.PrecisionMean
+	d := msgFromVar.PrecisionMean + offset*c
+	if c == 0 {
+		// The truncation of an improper cavity is undefined, keep the
+		// current message until the other factors have made it proper.
+		return 0, errImproperCavity
+	}
 	sqrtC := math.Sqrt(c)
 	dOnSqrtC := d / sqrtC
 	epsTimesSqrtC := epsilon * sqrtC
 	denom := 1.0 - wFunc(dOnSqrtC, epsTimesSqrtC)
 	newPrecision := c / denom
-	newPrecisionMean := (d + sqrtC*vFunc(dOnSqrtC, epsTimesSqrtC)) / denom
+	newPrecisionMean := (d+sqrtC*vFunc(dOnSqrtC, epsTimesSqrtC))/denom - offset*newPrecision
 	newMarginal := gaussian.NewFromPrecision(newPrecisionMean, newPrecision)
+	if !newMarginal.IsProper() {
+		return 0, errTruncationNotProper
+	}
 	newMsg := oldMsg.Mul(newMarginal).Div(oldMarginal)
 
 	msgBag.Put(msgIdx, newMsg)
 	varBag.Put(varIdx, newMarginal)
 
-	return newMarginal.Sub(oldMarginal)
+	return newMarginal.Sub(oldMarginal), nil
 }
 
 // GaussianGreaterThan calculates the greater than margin for the factor graph.
 func (gf GaussianFactors) GaussianGreaterThan(epsilon float64, varIdx int, varBag *collection.DistributionBag) Factor {
 	msgIdx := gf.msgBag.NextIndex()
 
+	var err error
 	updateMessage := func(i int) float64 {
 		if i != 0 {
 			panic("Index out of range.")
 		}
 
-		return gaussianGreaterThanOrWithinUpdateMessage(epsilon, msgIdx, varIdx, gf.msgBag, varBag,
+		var delta float64
+		delta, err = gaussianGreaterThanOrWithinUpdateMessage(epsilon, 0, msgIdx, varIdx, gf.msgBag, varBag,
 			VGreaterThan, WGreaterThan)
+		return delta
 	}
 	logNormalization := func() float64 {
 		marginal := varBag.Get(varIdx)
 		msg := gf.msgBag.Get(msgIdx)
 		msgFromVar := marginal.Div(msg)
 		logProdNorm := gaussian.LogProdNorm(msgFromVar, msg)
-		return -logProdNorm + math.Log(gaussian.NormCdf((msgFromVar.Mean()-epsilon)/msgFromVar.StdDev()))
+		return -logProdNorm + gaussian.LogNormCdf((msgFromVar.Mean()-epsilon)/msgFromVar.StdDev())
 	}
 	sendMessage := func(i int) float64 {
 		if i != 0 {
@@ -265,29 +352,42 @@ func (gf GaussianFactors) GaussianGreaterThan(epsilon float64, varIdx int, varBa
 		NumMessages:      1,
 		ResetMarginals:   func() { varBag.PutPriorAt(varIdx) },
 		SendMessage:      sendMessage,
+		Kind:             KindGreaterThan,
+		Variables:        []int{varIdx},
+		Message:          gf.messageFunc(msgIdx),
+		Err:              func() error { return err },
 	}
 }
 
 // GaussianWithin calculates the within margin for the factor graph.
 func (gf GaussianFactors) GaussianWithin(epsilon float64, varIdx int, varBag *collection.DistributionBag) Factor {
+	return gf.GaussianWithinOffset(epsilon, 0, varIdx, varBag)
+}
+
+// GaussianWithinOffset calculates the within margin for the factor graph
+// where the variable is shifted by offset, the absolute value of the
+// variable plus offset is at most epsilon. A greater than factor needs no
+// offset, GaussianGreaterThan with epsilon minus the offset is equivalent.
+func (gf GaussianFactors) GaussianWithinOffset(epsilon, offset float64, varIdx int, varBag *collection.DistributionBag) Factor {
 	msgIdx := gf.msgBag.NextIndex()
 
+	var err error
 	updateMessage := func(i int) float64 {
 		if i != 0 {
 			panic("Index out of range.")
 		}
 
-		return gaussianGreaterThanOrWithinUpdateMessage(epsilon, msgIdx, varIdx, gf.msgBag, varBag, VWithin, WWithin)
+		var delta float64
+		delta, err = gaussianGreaterThanOrWithinUpdateMessage(epsilon, offset, msgIdx, varIdx, gf.msgBag, varBag, VWithin, WWithin)
+		return delta
 	}
 	logNormalization := func() float64 {
 		marginal := varBag.Get(varIdx)
 		msg := gf.msgBag.Get(msgIdx)
 		msgFromVar := marginal.Div(msg)
 		logProdNorm := gaussian.LogProdNorm(msgFromVar, msg)
-		mean := msgFromVar.Mean()
 		stdDev := msgFromVar.StdDev()
-		z := gaussian.NormCdf((epsilon-mean)/stdDev) - gaussian.NormCdf((-epsilon-mean)/stdDev)
-		return -logProdNorm + math.Log(z)
+		return -logProdNorm + logWithin((msgFromVar.Mean()+offset)/stdDev, epsilon/stdDev)
 	}
 	sendMessage := func(i int) float64 {
 		if i != 0 {
@@ -303,5 +403,9 @@ func (gf GaussianFactors) GaussianWithin(epsilon float64, varIdx int, varBag *co
 		NumMessages:      1,
 		ResetMarginals:   func() { varBag.PutPriorAt(varIdx) },
 		SendMessage:      sendMessage,
+		Kind:             KindWithin,
+		Variables:        []int{varIdx},
+		Message:          gf.messageFunc(msgIdx),
+		Err:              func() error { return err },
 	}
 }
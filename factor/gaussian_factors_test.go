@@ -0,0 +1,121 @@
+package factor
+
+import (
+	"testing"
+
+	"github.com/mafredri/go-trueskill/collection"
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestGaussianWeightedSumN(t *testing.T) {
+	gf := NewGaussianFactors()
+	varBag := collection.NewDistributionBag(gaussian.NewFromPrecision(0, 0))
+
+	weights := []float64{2, -1, 0.5, 0}
+	means := []float64{1, 2, 4, 8}
+	variances := []float64{1, 2, 4, 8}
+	var terms []int
+	for i := range weights {
+		v := varBag.NextIndex()
+		gf.GaussianPrior(means[i], variances[i], v, varBag).UpdateMessage(0)
+		terms = append(terms, v)
+	}
+	sum := varBag.NextIndex()
+	f := gf.GaussianWeightedSumN(weights, sum, terms, varBag)
+
+	if f.NumMessages != 5 {
+		t.Fatalf("NumMessages == %d, want 5", f.NumMessages)
+	}
+
+	// Sum of N(1, 1)*2, N(2, 2)*-1 and N(4, 4)*0.5 is N(2, 7).
+	f.UpdateMessage(0)
+	if got := varBag.Get(sum); !mathextra.Float64AlmostEq(got.Mean(), 2, 1e-12) ||
+		!mathextra.Float64AlmostEq(got.Variance(), 7, 1e-12) {
+		t.Errorf("sum == N(%v, %v), want N(2, 7)", got.Mean(), got.Variance())
+	}
+
+	// Observing the sum as N(9, 1) sends N((9-(-2)-2)/2, (1+2+1)/4) to the
+	// first term.
+	obs := gf.GaussianPrior(9, 1, sum, varBag)
+	obs.UpdateMessage(0)
+	f.UpdateMessage(1)
+	if got := f.Message(1); !mathextra.Float64AlmostEq(got.Mean(), 4.5, 1e-12) ||
+		!mathextra.Float64AlmostEq(got.Variance(), 1, 1e-12) {
+		t.Errorf("Message(1) == N(%v, %v), want N(4.5, 1)", got.Mean(), got.Variance())
+	}
+
+	// A term with zero weight is not affected by the sum.
+	f.UpdateMessage(4)
+	if got := f.Message(4); got.Precision != 0 {
+		t.Errorf("Message(4).Precision == %v, want 0", got.Precision)
+	}
+	if got := varBag.Get(terms[3]); got.Mean() != 8 {
+		t.Errorf("terms[3].Mean() == %v, want 8", got.Mean())
+	}
+}
+
+func TestGaussianGreaterThanImproperCavity(t *testing.T) {
+	gf := NewGaussianFactors()
+	varBag := collection.NewDistributionBag(gaussian.NewFromPrecision(0, 0))
+	v := varBag.NextIndex()
+	f := gf.GaussianGreaterThan(0.5, v, varBag)
+
+	// Without a message from the rest of the graph the cavity is uniform
+	// and the truncation is skipped.
+	if delta := f.UpdateMessage(0); delta != 0 {
+		t.Errorf("UpdateMessage(0) == %v, want 0", delta)
+	}
+	if got := f.Message(0); !got.Equals(gaussian.NewFromPrecision(0, 0)) {
+		t.Errorf("Message(0) == %#v, want uniform", got)
+	}
+	if f.Err() == nil {
+		t.Error("Err() after truncating an improper cavity did not return an error")
+	}
+
+	// A negative precision is clamped to the uniform cavity as well.
+	varBag.Put(v, gaussian.NewFromPrecision(1, -1))
+	if delta := f.UpdateMessage(0); delta != 0 {
+		t.Errorf("UpdateMessage(0) == %v, want 0", delta)
+	}
+
+	varBag.Put(v, gaussian.NewFromMeanAndVariance(1, 1))
+	f.UpdateMessage(0)
+	if got := varBag.Get(v); !got.IsProper() || got.Mean() <= 1 {
+		t.Errorf("marginal == %#v, want a proper gaussian with mean above 1", got)
+	}
+	if err := f.Err(); err != nil {
+		t.Errorf("Err() after a successful update == %v, want nil", err)
+	}
+}
+
+func TestGaussianGreaterThanNotProper(t *testing.T) {
+	g := NewGraph()
+	v := g.NewVariable("v")
+	g.Prior("prior", v, -1e10, 1)
+	g.GreaterThan("greaterThan", v, 0)
+
+	// The truncation is so extreme that the variance of the marginal
+	// rounds to zero, the update is rejected.
+	g.Run(1e-6)
+	if got := g.Marginal(v); !got.Equals(gaussian.NewFromMeanAndVariance(-1e10, 1)) {
+		t.Errorf("Marginal() == %#v, want the prior", got)
+	}
+	if g.Err() == nil {
+		t.Error("Err() did not return an error")
+	}
+}
+
+func TestGaussianLikeliehoodNegativeCavity(t *testing.T) {
+	gf := NewGaussianFactors()
+	varBag := collection.NewDistributionBag(gaussian.NewFromPrecision(0, 0))
+	skill := varBag.NextIndex()
+	perf := varBag.NextIndex()
+	f := gf.GaussianLikeliehood(1, skill, perf, varBag, varBag)
+
+	varBag.Put(skill, gaussian.NewFromPrecision(1, -0.5))
+	f.UpdateMessage(1)
+	if got := varBag.Get(perf); !got.IsFinite() || got.Precision < 0 {
+		t.Errorf("performance == %#v, want a finite gaussian with non-negative precision", got)
+	}
+}
@@ -0,0 +1,185 @@
+package factor
+
+import (
+	"github.com/mafredri/go-trueskill/collection"
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+// Graph is a factor graph of gaussian variables. Variables are created with
+// NewVariable and connected by the factors added with the methods of the
+// graph. The marginals of the variables are inferred by running a schedule,
+// either the one returned by Schedule or one built from the UpdateMessage
+// functions of the factors.
+type Graph struct {
+	gf      GaussianFactors
+	varBag  *collection.DistributionBag
+	names   []string
+	factors List
+}
+
+// NewGraph returns an empty factor graph.
+func NewGraph() *Graph {
+	return &Graph{
+		gf:     NewGaussianFactors(),
+		varBag: collection.NewDistributionBag(gaussian.NewFromPrecision(0, 0)),
+	}
+}
+
+// NewVariable adds a variable to the graph and returns its index. The
+// marginal of a new variable is uniform.
+func (g *Graph) NewVariable(name string) int {
+	g.names = append(g.names, name)
+	return g.varBag.NextIndex()
+}
+
+// NumVariables returns the number of variables in the graph.
+func (g *Graph) NumVariables() int {
+	return g.varBag.Len()
+}
+
+// VariableName returns the name of variable v.
+func (g *Graph) VariableName(v int) string {
+	return g.names[v]
+}
+
+// Marginal returns the current marginal of variable v.
+func (g *Graph) Marginal(v int) gaussian.Gaussian {
+	return g.varBag.Get(v)
+}
+
+// Factors returns the factors of the graph in the order they were added.
+func (g *Graph) Factors() []Factor {
+	return g.factors.Factors()
+}
+
+func (g *Graph) add(name string, f Factor) Factor {
+	f.Name = name
+	return g.factors.Add(f)
+}
+
+// Prior adds a factor setting the prior of v to a gaussian with the provided
+// mean and variance.
+func (g *Graph) Prior(name string, v int, mean, variance float64) Factor {
+	return g.add(name, g.gf.GaussianPrior(mean, variance, v, g.varBag))
+}
+
+// Likelihood adds a factor where x is a gaussian with mean y and the
+// provided variance.
+func (g *Graph) Likelihood(name string, x, y int, variance float64) Factor {
+	return g.add(name, g.gf.GaussianLikeliehood(variance, x, y, g.varBag, g.varBag))
+}
+
+// WeightedSum adds a factor where sum is the sum of the terms multiplied by
+// their weights.
+func (g *Graph) WeightedSum(name string, sum int, weights []float64, terms ...int) Factor {
+	return g.add(name, g.gf.GaussianWeightedSumN(weights, sum, terms, g.varBag))
+}
+
+// GreaterThan adds a factor observing that v is greater than epsilon.
+func (g *Graph) GreaterThan(name string, v int, epsilon float64) Factor {
+	return g.add(name, g.gf.GaussianGreaterThan(epsilon, v, g.varBag))
+}
+
+// Within adds a factor observing that the absolute value of v is at most
+// epsilon.
+func (g *Graph) Within(name string, v int, epsilon float64) Factor {
+	return g.add(name, g.gf.GaussianWithin(epsilon, v, g.varBag))
+}
+
+// WithinOffset adds a factor observing that the absolute value of v plus
+// offset is at most epsilon.
+func (g *Graph) WithinOffset(name string, v int, epsilon, offset float64) Factor {
+	return g.add(name, g.gf.GaussianWithinOffset(epsilon, offset, v, g.varBag))
+}
+
+// Custom is a user defined factor. The methods are passed the current
+// marginals of the variables connected to the factor and the current
+// messages from the factor to them, in the order the variables were passed
+// to Graph.Custom.
+type Custom interface {
+	// Update returns the new message from the factor to variable i. A
+	// message that is not finite is ignored and the previous message kept.
+	Update(i int, marginals, messages []gaussian.Gaussian) gaussian.Gaussian
+
+	// LogNormalization returns the contribution of the factor to the log
+	// evidence of the graph, in addition to the normalization of its
+	// messages (see List.LogNormalization). Factors whose messages are
+	// exact, like the likelihood, return the log ratio normalization of
+	// all but one of their variables.
+	LogNormalization(marginals, messages []gaussian.Gaussian) float64
+}
+
+// Custom adds a user defined factor connected to vars.
+func (g *Graph) Custom(name string, c Custom, vars ...int) Factor {
+	var msgIdx []int
+	for range vars {
+		msgIdx = append(msgIdx, g.gf.msgBag.NextIndex())
+	}
+
+	current := func() (marginals, messages []gaussian.Gaussian) {
+		for i, v := range vars {
+			marginals = append(marginals, g.varBag.Get(v))
+			messages = append(messages, g.gf.msgBag.Get(msgIdx[i]))
+		}
+		return marginals, messages
+	}
+	updateMessage := func(i int) float64 {
+		marginals, messages := current()
+		newMsg := c.Update(i, marginals, messages)
+		if !newMsg.IsFinite() {
+			return 0
+		}
+		newMarginal := marginals[i].Div(messages[i]).ClampPrecision().Mul(newMsg)
+
+		g.gf.msgBag.Put(msgIdx[i], newMsg)
+		g.varBag.Put(vars[i], newMarginal)
+
+		return newMarginal.Sub(marginals[i])
+	}
+	logNormalization := func() float64 {
+		return c.LogNormalization(current())
+	}
+	resetMarginals := func() {
+		for _, v := range vars {
+			g.varBag.PutPriorAt(v)
+		}
+	}
+	sendMessage := func(i int) float64 {
+		return sendMessageHelper(msgIdx[i], vars[i], g.gf.msgBag, g.varBag)
+	}
+
+	return g.add(name, Factor{
+		UpdateMessage:    updateMessage,
+		LogNormalization: logNormalization,
+		NumMessages:      len(vars),
+		ResetMarginals:   resetMarginals,
+		SendMessage:      sendMessage,
+		Kind:             KindCustom,
+		Variables:        append([]int(nil), vars...),
+		Message:          g.gf.messageFunc(msgIdx...),
+	})
+}
+
+// Schedule returns a schedule derived from the factors of the graph, see
+// Schedule.
+func (g *Graph) Schedule(maxDelta float64) schedule.Runner {
+	return Schedule(g.Factors(), maxDelta)
+}
+
+// Run runs the schedule returned by Schedule and returns the delta.
+func (g *Graph) Run(maxDelta float64) float64 {
+	return schedule.Run(g.Schedule(maxDelta), -1)
+}
+
+// Err returns the error of the first factor whose last message update
+// failed, see List.Err. It should be called after the schedule has been run.
+func (g *Graph) Err() error {
+	return g.factors.Err()
+}
+
+// LogNormalization returns the log evidence of the graph, it should be
+// called after the schedule has been run.
+func (g *Graph) LogNormalization() float64 {
+	return g.factors.LogNormalization()
+}
@@ -0,0 +1,107 @@
+package factor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestGraphTwoPlayerMatch(t *testing.T) {
+	const (
+		mu, sigma, beta = 25.0, 25.0 / 3, 25.0 / 6
+		epsilon         = 0.0
+	)
+
+	g := NewGraph()
+	var skills, perfs []int
+	for _, name := range []string{"0", "1"} {
+		skill := g.NewVariable("skill" + name)
+		perf := g.NewVariable("perf" + name)
+		g.Prior("prior"+name, skill, mu, sigma*sigma)
+		g.Likelihood("likelihood"+name, perf, skill, beta*beta)
+		skills, perfs = append(skills, skill), append(perfs, perf)
+	}
+	diff := g.NewVariable("diff")
+	g.WeightedSum("sum", diff, []float64{1, -1}, perfs...)
+	g.GreaterThan("greaterThan", diff, epsilon)
+
+	g.Run(1e-6)
+
+	// Closed form update of the winner and loser of a two player match.
+	c := math.Sqrt(2*beta*beta + 2*sigma*sigma)
+	v, w := VGreaterThan(0, epsilon/c), WGreaterThan(0, epsilon/c)
+	wantMu := []float64{mu + sigma*sigma/c*v, mu - sigma*sigma/c*v}
+	wantSigma := math.Sqrt(sigma * sigma * (1 - sigma*sigma/(c*c)*w))
+
+	for i, skill := range skills {
+		got := g.Marginal(skill)
+		if !mathextra.Float64AlmostEq(got.Mean(), wantMu[i], 1e-6) {
+			t.Errorf("Marginal(%s).Mean() == %v, want %v", g.VariableName(skill), got.Mean(), wantMu[i])
+		}
+		if !mathextra.Float64AlmostEq(got.StdDev(), wantSigma, 1e-6) {
+			t.Errorf("Marginal(%s).StdDev() == %v, want %v", g.VariableName(skill), got.StdDev(), wantSigma)
+		}
+	}
+	if want := math.Log(0.5); !mathextra.Float64AlmostEq(g.LogNormalization(), want, 1e-6) {
+		t.Errorf("LogNormalization() == %v, want %v", g.LogNormalization(), want)
+	}
+}
+
+func TestGraphWeightedSum(t *testing.T) {
+	g := NewGraph()
+	var terms []int
+	for _, mean := range []float64{1, 2, 3} {
+		v := g.NewVariable("x")
+		g.Prior("prior", v, mean, 1)
+		terms = append(terms, v)
+	}
+	sum := g.NewVariable("sum")
+	g.WeightedSum("sum", sum, []float64{1, 1, 1}, terms...)
+	g.Prior("observation", sum, 9, 1)
+
+	g.Run(1e-6)
+
+	// The sum has prior N(6, 3) which is observed as N(9, 1).
+	if got := g.Marginal(sum); !mathextra.Float64AlmostEq(got.Mean(), 8.25, 1e-6) ||
+		!mathextra.Float64AlmostEq(got.Variance(), 0.75, 1e-6) {
+		t.Errorf("Marginal(sum) == N(%v, %v), want N(8.25, 0.75)", got.Mean(), got.Variance())
+	}
+	if got := g.Marginal(terms[0]); !mathextra.Float64AlmostEq(got.Mean(), 1.75, 1e-6) ||
+		!mathextra.Float64AlmostEq(got.Variance(), 0.75, 1e-6) {
+		t.Errorf("Marginal(x0) == N(%v, %v), want N(1.75, 0.75)", got.Mean(), got.Variance())
+	}
+}
+
+// fixed is a custom factor that sends a fixed message, like a prior.
+type fixed struct{ msg gaussian.Gaussian }
+
+func (f fixed) Update(i int, marginals, messages []gaussian.Gaussian) gaussian.Gaussian {
+	return f.msg
+}
+
+func (f fixed) LogNormalization(marginals, messages []gaussian.Gaussian) float64 {
+	return 0
+}
+
+func TestGraphCustom(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable("x")
+	y := g.NewVariable("y")
+	f := g.Custom("fixed", fixed{gaussian.NewFromMeanAndVariance(3, 4)}, x)
+	g.Likelihood("likelihood", y, x, 1)
+
+	g.Run(1e-6)
+
+	if f.Kind != KindCustom || f.Name != "fixed" || f.NumMessages != 1 {
+		t.Errorf("Custom() returned %s factor %q with %d messages", f.Kind, f.Name, f.NumMessages)
+	}
+	if got := g.Marginal(y); !mathextra.Float64AlmostEq(got.Mean(), 3, 1e-6) ||
+		!mathextra.Float64AlmostEq(got.Variance(), 5, 1e-6) {
+		t.Errorf("Marginal(y) == N(%v, %v), want N(3, 5)", got.Mean(), got.Variance())
+	}
+	if got := f.Message(0); got.Mean() != 3 {
+		t.Errorf("Message(0).Mean() == %v, want 3", got.Mean())
+	}
+}
@@ -4,62 +4,93 @@ import (
 	"math"
 
 	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
 )
 
 // VGreaterThan returns the additive correction for a single-sided truncated
 // gaussian with unit variance.
 func VGreaterThan(t, epsilon float64) float64 {
-	denom := gaussian.NormCdf(t - epsilon)
-	if denom < 2.222758749e-162 {
-		return -t + epsilon
-	}
-
-	return gaussian.NormPdf(t-epsilon) / denom
+	// NormPdf(t-epsilon) / NormCdf(t-epsilon) without the underflow of both
+	// for large mismatches.
+	return 1 / gaussian.MillsRatio(epsilon-t)
 }
 
 // WGreaterThan returns the multiplicative correction for a single-sided
 // truncated gaussian with unit variance.
 func WGreaterThan(t, epsilon float64) float64 {
-	var denom = gaussian.NormCdf(t - epsilon)
-	if denom < 2.222758749e-162 {
-		if t < 0.0 {
+	if x := epsilon - t; x >= mathextra.MillsRatioDirect {
+		// V approaches x, evaluate V * (V - x) without cancellation.
+		if math.IsInf(x, 1) {
 			return 1.0
 		}
-		return 0.0
+		d := mathextra.InvMillsRatioExcess(x)
+		return (x + d) * d
 	}
 
 	vt := VGreaterThan(t, epsilon)
 	return vt * (vt + t - epsilon)
 }
 
+// withinRatios returns the terms of the double-sided truncation, all scaled
+// by the same factor: the difference of the cdfs, the difference of the pdfs
+// and the difference of the pdfs multiplied by their points.
+func withinRatios(t, epsilon float64) (cdf, pdf, xpdf float64) {
+	v := math.Abs(t)
+	a, b := v-epsilon, v+epsilon
+
+	if a <= 0 {
+		cdf = gaussian.NormCdf(-a) - gaussian.NormCdf(-b)
+		pdf = gaussian.NormPdf(b) - gaussian.NormPdf(a)
+		xpdf = b*gaussian.NormPdf(b) - a*gaussian.NormPdf(a)
+		return cdf, pdf, xpdf
+	}
+
+	// Relative to NormPdf(a) using Mills ratios, which avoids the underflow
+	// of the cdfs and pdfs for large mismatches. e is NormPdf(b) /
+	// NormPdf(a).
+	e := math.Exp(-2 * v * epsilon)
+	cdf = gaussian.MillsRatio(a) - e*gaussian.MillsRatio(b)
+	pdf = math.Expm1(-2 * v * epsilon)
+	xpdf = b*e - a
+	return cdf, pdf, xpdf
+}
+
+// logWithin returns the natural logarithm of the probability that a gaussian
+// with unit variance and mean t is within epsilon of zero.
+func logWithin(t, epsilon float64) float64 {
+	cdf, _, _ := withinRatios(t, epsilon)
+	if a := math.Abs(t) - epsilon; a > 0 {
+		return math.Log(cdf) - a*a/2 - mathextra.LogSqrt2Pi
+	}
+	return math.Log(cdf)
+}
+
 // VWithin returns the additive correction for a double-sided truncated gaussian
 // with unit variance.
 func VWithin(t, epsilon float64) float64 {
-	v := math.Abs(t)
-	denom := gaussian.NormCdf(epsilon-v) - gaussian.NormCdf(-epsilon-v)
-	if denom < 2.222758749e-162 {
+	cdf, pdf, _ := withinRatios(t, epsilon)
+	if !(cdf > 0) {
+		// Degenerate margin, fall back to the asymptotes.
 		if t < 0.0 {
 			return -t - epsilon
 		}
 		return -t + epsilon
 	}
 
-	num := gaussian.NormPdf(-epsilon-v) - gaussian.NormPdf(epsilon-v)
 	if t < 0.0 {
-		return -num / denom
+		return -pdf / cdf
 	}
-	return num / denom
+	return pdf / cdf
 }
 
 // WWithin returns the multiplicative correction for a double-sided truncated
 // gaussian with unit variance.
 func WWithin(t, epsilon float64) float64 {
-	v := math.Abs(t)
-	denom := gaussian.NormCdf(epsilon-v) - gaussian.NormCdf(-epsilon-v)
-	if denom < 2.222758749e-162 {
+	cdf, _, xpdf := withinRatios(t, epsilon)
+	if !(cdf > 0) {
 		return 1.0
 	}
 
 	vt := VWithin(t, epsilon)
-	return vt*vt + ((epsilon-v)*gaussian.NormPdf(epsilon-v)-(-epsilon-v)*gaussian.NormPdf(-epsilon-v))/denom
+	return vt*vt + xpdf/cdf
 }
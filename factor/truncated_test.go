@@ -53,3 +53,54 @@ func TestWWithin(t *testing.T) {
 		t.Errorf("WWithin(%f, %f) == %.6f, want %.6f", tVar, eps, r, want)
 	}
 }
+
+// Reference values for large mismatches computed with 1200 digit arithmetic.
+func TestTruncatedTails(t *testing.T) {
+	tests := []struct {
+		t, epsilon float64
+		v, w       float64
+		within     bool
+	}{
+		{-1e4, 0, 10000.000099999997, 0.99999999000000062, false},
+		{-100, 0, 100.00999800099926, 0.99990005995005171, false},
+		{-40, 0, 40.024968847207262, 0.99937733162140863, false},
+		{-20, 0, 20.049753068527849, 0.99753673838494783, false},
+		{-5, 0, 5.1865039671258417, 0.96730356538288775, false},
+		{5, 0, 1.4867199409049056e-06, 7.4336019148607111e-06, false},
+		{0.7495591915280050, 0.0631282276750071, -0.7485641611574948, 0.99867290841890843, true},
+		{-30, 0.5, 29.533820844167892, 0.99885875245582489, true},
+		{50, 1, -49.020391198838453, 0.99958454407441699, true},
+		{3, 2, -1.5251286609436421, 0.80092625457584465, true},
+	}
+	for _, tt := range tests {
+		v, w := VGreaterThan(tt.t, tt.epsilon), WGreaterThan(tt.t, tt.epsilon)
+		name := "GreaterThan"
+		if tt.within {
+			v, w = VWithin(tt.t, tt.epsilon), WWithin(tt.t, tt.epsilon)
+			name = "Within"
+		}
+		if !mathextra.Float64AlmostEq(v/tt.v, 1, 1e-12) {
+			t.Errorf("V%s(%g, %g) == %.17g, want %.17g", name, tt.t, tt.epsilon, v, tt.v)
+		}
+		if !mathextra.Float64AlmostEq(w/tt.w, 1, 1e-12) {
+			t.Errorf("W%s(%g, %g) == %.17g, want %.17g", name, tt.t, tt.epsilon, w, tt.w)
+		}
+	}
+}
+
+// Reference values computed with 1200 digit arithmetic.
+func TestLogWithin(t *testing.T) {
+	tests := []struct {
+		t, epsilon, want float64
+	}{
+		{0.7495591915280050, 0.0631282276750071, -3.2695891788557385},
+		{-30, 0.5, -439.4294746091503},
+		{50, 1, -1205.3111748916654},
+		{3, 2, -1.8410234517684079},
+	}
+	for _, tt := range tests {
+		if got := logWithin(tt.t, tt.epsilon); !mathextra.Float64AlmostEq(got/tt.want, 1, 1e-12) {
+			t.Errorf("logWithin(%g, %g) == %.17g, want %.17g", tt.t, tt.epsilon, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package trueskill
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+// FactorGraph is a snapshot of the factor graph of a match after inference,
+// used to inspect how a match was rated. It can be encoded as JSON or
+// written as Graphviz DOT with WriteDOT.
+type FactorGraph struct {
+	Variables []GraphVariable `json:"variables"`
+	Factors   []GraphFactor   `json:"factors"`
+	Schedule  schedule.Node   `json:"schedule"` // The schedule that was run
+}
+
+// GraphVariable is a variable in the factor graph.
+type GraphVariable struct {
+	Name     string            `json:"name"`
+	Marginal gaussian.Gaussian `json:"marginal"`
+}
+
+// GraphFactor is a factor in the factor graph. Messages[i] is the current
+// message from the factor to the variable named Variables[i].
+type GraphFactor struct {
+	Name      string              `json:"name"`
+	Kind      string              `json:"kind"`
+	Variables []string            `json:"variables"`
+	Messages  []gaussian.Gaussian `json:"messages"`
+}
+
+// FactorGraph rates a match like Rate and returns a snapshot of its factor
+// graph and schedule. The skills of the players are not adjusted.
+func (ts Config) FactorGraph(players []Player, draws []bool) (FactorGraph, error) {
+	if len(players) < 2 {
+		return FactorGraph{}, errTooFewPlayers
+	}
+	if len(draws) != len(players)-1 {
+		return FactorGraph{}, fmt.Errorf("draws slice should have length %d but have %d instead", len(players)-1, len(draws))
+	}
+	if err := checkPlayers(players); err != nil {
+		return FactorGraph{}, err
+	}
+
+	inf := ts.infer(players, draws)
+
+	var g FactorGraph
+	names := inf.skillFactors.variableNames
+	for i, name := range names {
+		g.Variables = append(g.Variables, GraphVariable{Name: name, Marginal: inf.varBag.Get(i)})
+	}
+	for _, f := range inf.factorList.Factors() {
+		gf := GraphFactor{Name: f.Name, Kind: f.Kind}
+		for i, v := range f.Variables {
+			gf.Variables = append(gf.Variables, names[v])
+			gf.Messages = append(gf.Messages, f.Message(i))
+		}
+		g.Factors = append(g.Factors, gf)
+	}
+	g.Schedule = schedule.Describe(inf.schedule)
+
+	return g, nil
+}
+
+// WriteDOT writes the factor graph as an undirected Graphviz DOT graph.
+// Variables are drawn as ellipses labeled with their marginal, factors as
+// boxes and the edges are labeled with the message from the factor.
+func (g FactorGraph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph factors {")
+	for _, v := range g.Variables {
+		label := v.Name + "\n" + gaussianLabel(v.Marginal)
+		fmt.Fprintf(bw, "\t%s [label=%s shape=ellipse];\n", strconv.Quote("v:"+v.Name), strconv.Quote(label))
+	}
+	for _, f := range g.Factors {
+		fmt.Fprintf(bw, "\t%s [label=%s shape=box];\n", strconv.Quote("f:"+f.Name), strconv.Quote(f.Name))
+		for i, v := range f.Variables {
+			fmt.Fprintf(bw, "\t%s -- %s [label=%s];\n",
+				strconv.Quote("f:"+f.Name), strconv.Quote("v:"+v), strconv.Quote(gaussianLabel(f.Messages[i])))
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func gaussianLabel(g gaussian.Gaussian) string {
+	if g.Precision == 0 {
+		return "uniform"
+	}
+	return fmt.Sprintf("μ=%.4g σ=%.4g", g.Mean(), g.StdDev())
+}
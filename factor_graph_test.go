@@ -0,0 +1,95 @@
+package trueskill
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/factor"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+func TestFactorGraph(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer(), ts.NewPlayer()}
+	draws := []bool{false, true}
+
+	g, err := ts.FactorGraph(players, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ts.Rate(players, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantVars := []string{"skill0", "skill1", "skill2", "perf0", "perf1", "perf2", "diff0", "diff1"}
+	if len(g.Variables) != len(wantVars) {
+		t.Fatalf("got %d variables, want %d", len(g.Variables), len(wantVars))
+	}
+	for i, v := range g.Variables {
+		if v.Name != wantVars[i] {
+			t.Errorf("Variables[%d].Name == %q, want %q", i, v.Name, wantVars[i])
+		}
+	}
+	for i, p := range r.Players {
+		if g.Variables[i].Marginal != p.Gaussian {
+			t.Errorf("Variables[%d].Marginal == %v, want %v", i, g.Variables[i].Marginal, p.Gaussian)
+		}
+	}
+
+	kinds := make(map[string]int)
+	for _, f := range g.Factors {
+		kinds[f.Kind]++
+		if len(f.Messages) != len(f.Variables) {
+			t.Errorf("factor %s has %d messages for %d variables", f.Name, len(f.Messages), len(f.Variables))
+		}
+	}
+	wantKinds := map[string]int{
+		factor.KindPrior:       3,
+		factor.KindLikelihood:  3,
+		factor.KindWeightedSum: 2,
+		factor.KindGreaterThan: 1,
+		factor.KindWithin:      1,
+	}
+	for k, n := range wantKinds {
+		if kinds[k] != n {
+			t.Errorf("got %d factors of kind %s, want %d", kinds[k], k, n)
+		}
+	}
+	if f := g.Factors[6]; f.Name != "sum0" || strings.Join(f.Variables, ",") != "diff0,perf0,perf1" {
+		t.Errorf("Factors[6] == %s(%v), want sum0(diff0,perf0,perf1)", f.Name, f.Variables)
+	}
+
+	if countKind(g.Schedule, schedule.KindLoop) == 0 {
+		t.Error("schedule has no loop")
+	}
+
+	if _, err := json.Marshal(g); err != nil {
+		t.Error(err)
+	}
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{"graph factors {", `"f:within1" -- "v:diff1"`, `"v:skill0"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("WriteDOT() output missing %q:\n%s", want, dot)
+		}
+	}
+
+	buf.Reset()
+	g = FactorGraph{Variables: []GraphVariable{{Name: `a "b" \c`}}}
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := `label="a \"b\" \\c\nuniform"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("WriteDOT() output missing %q:\n%s", want, buf.String())
+	}
+
+	if _, err := ts.FactorGraph(players[:1], nil); err == nil {
+		t.Error("FactorGraph() with one player returned no error")
+	}
+}
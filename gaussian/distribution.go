@@ -0,0 +1,93 @@
+package gaussian
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+// Pdf returns the probability density of the gaussian at x.
+func (a Gaussian) Pdf(x float64) float64 {
+	return math.Exp(a.LogPdf(x))
+}
+
+// LogPdf returns the natural logarithm of the probability density of the
+// gaussian at x.
+func (a Gaussian) LogPdf(x float64) float64 {
+	// With z = x - mean, -z^2/(2*variance) = -(precision*x - precisionMean)^2 / (2*precision).
+	d := a.Precision*x - a.PrecisionMean
+	return -mathextra.LogSqrt2Pi + math.Log(a.Precision)/2 - d*d/(2*a.Precision)
+}
+
+// Cdf returns the cumulative distribution function of the gaussian at x.
+func (a Gaussian) Cdf(x float64) float64 {
+	return NormCdf((x - a.Mean()) / a.StdDev())
+}
+
+// Quantile returns the point below which the probability of the gaussian is p,
+// the inverse of Cdf.
+func (a Gaussian) Quantile(p float64) float64 {
+	return a.Mean() + a.StdDev()*NormPpf(p)
+}
+
+// Sample returns a random sample from the gaussian using r as the source of
+// randomness.
+func (a Gaussian) Sample(r *rand.Rand) float64 {
+	return a.Mean() + a.StdDev()*r.NormFloat64()
+}
+
+// KL returns the Kullback-Leibler divergence of b from a, KL(a||b), the
+// information lost when b is used to approximate a.
+func (a Gaussian) KL(b Gaussian) float64 {
+	meanDiff := a.Mean() - b.Mean()
+	return (math.Log(a.Precision/b.Precision) + b.Precision*(a.Variance()+meanDiff*meanDiff) - 1) / 2
+}
+
+// Bhattacharyya returns the Bhattacharyya distance between two gaussians.
+func (a Gaussian) Bhattacharyya(b Gaussian) float64 {
+	varSum := a.Variance() + b.Variance()
+	meanDiff := a.Mean() - b.Mean()
+	return meanDiff*meanDiff/(4*varSum) + math.Log(varSum/(2*a.StdDev()*b.StdDev()))/2
+}
+
+// Hellinger returns the Hellinger distance between two gaussians, between
+// zero for equal gaussians and one for gaussians without overlap.
+func (a Gaussian) Hellinger(b Gaussian) float64 {
+	return math.Sqrt(-math.Expm1(-a.Bhattacharyya(b)))
+}
+
+// Scale returns the distribution of the gaussian variable multiplied by c.
+// The constant c must not be zero, the product would be exactly zero which a
+// gaussian cannot represent. Scale(0) returns a gaussian that is not finite,
+// it is rejected by Check and IsProper.
+func (a Gaussian) Scale(c float64) Gaussian {
+	return NewFromPrecision(a.PrecisionMean/c, a.Precision/(c*c))
+}
+
+// Shift returns the distribution of the gaussian variable plus d.
+func (a Gaussian) Shift(d float64) Gaussian {
+	return NewFromPrecision(a.PrecisionMean+d*a.Precision, a.Precision)
+}
+
+// Sum returns the distribution of the sum of two independent gaussian
+// variables.
+func (a Gaussian) Sum(b Gaussian) Gaussian {
+	return NewFromMeanAndVariance(a.Mean()+b.Mean(), a.Variance()+b.Variance())
+}
+
+// Diff returns the distribution of the difference of two independent gaussian
+// variables, a minus b.
+func (a Gaussian) Diff(b Gaussian) Gaussian {
+	return NewFromMeanAndVariance(a.Mean()-b.Mean(), a.Variance()+b.Variance())
+}
+
+// String returns the mean and standard deviation of the gaussian, or the
+// precision form when the precision is not positive.
+func (a Gaussian) String() string {
+	if !(a.Precision > 0) {
+		return fmt.Sprintf("Gaussian(precisionMean=%g precision=%g)", a.PrecisionMean, a.Precision)
+	}
+	return fmt.Sprintf("Gaussian(mean=%.3f stddev=%.3f)", a.Mean(), a.StdDev())
+}
@@ -0,0 +1,114 @@
+package gaussian
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestGaussianPdfCdfQuantile(t *testing.T) {
+	g := NewFromMeanAndStdDev(1, 2)
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Pdf(1)", g.Pdf(1), 0.19947114020071635},
+		{"Pdf(3)", g.Pdf(3), 0.12098536225957168},
+		{"LogPdf(3)", g.LogPdf(3), math.Log(0.12098536225957168)},
+		{"Cdf(3)", g.Cdf(3), 0.8413447460685429},
+		{"Cdf(1)", g.Cdf(1), 0.5},
+		{"Quantile(0.8413447460685429)", g.Quantile(0.8413447460685429), 3},
+		{"Quantile(0.5)", g.Quantile(0.5), 1},
+	}
+	for _, tt := range tests {
+		if !mathextra.Float64AlmostEq(tt.got, tt.want, epsilon) {
+			t.Errorf("%s == %.16f, want %.16f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGaussianDivergences(t *testing.T) {
+	a := NewFromMeanAndStdDev(0, 1)
+	b := NewFromMeanAndStdDev(1, 2)
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"KL", a.KL(b), math.Ln2 + 0.25 - 0.5},
+		{"KL(self)", a.KL(a), 0},
+		{"Bhattacharyya", a.Bhattacharyya(b), 0.05 + math.Log(1.25)/2},
+		{"Bhattacharyya(self)", b.Bhattacharyya(b), 0},
+		{"Hellinger", a.Hellinger(b), math.Sqrt(1 - math.Exp(-(0.05 + math.Log(1.25)/2)))},
+		{"Hellinger(reversed)", b.Hellinger(a), a.Hellinger(b)},
+	}
+	for _, tt := range tests {
+		if !mathextra.Float64AlmostEq(tt.got, tt.want, epsilon) {
+			t.Errorf("%s == %.16f, want %.16f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGaussianTransforms(t *testing.T) {
+	a := NewFromMeanAndStdDev(1, 2)
+	b := NewFromMeanAndStdDev(3, 1)
+
+	tests := []struct {
+		name           string
+		got            Gaussian
+		mean, variance float64
+	}{
+		{"Scale(-2)", a.Scale(-2), -2, 16},
+		{"Shift(3)", a.Shift(3), 4, 4},
+		{"Sum", a.Sum(b), 4, 5},
+		{"Diff", a.Diff(b), -2, 5},
+	}
+	for _, tt := range tests {
+		if !mathextra.Float64AlmostEq(tt.got.Mean(), tt.mean, epsilon) ||
+			!mathextra.Float64AlmostEq(tt.got.Variance(), tt.variance, epsilon) {
+			t.Errorf("%s == N(%v, %v), want N(%v, %v)", tt.name, tt.got.Mean(), tt.got.Variance(), tt.mean, tt.variance)
+		}
+	}
+
+	for _, g := range []Gaussian{a, NewFromMeanAndStdDev(0, 1), {}} {
+		if err := g.Scale(0).Check(); err == nil {
+			t.Errorf("%v.Scale(0).Check() did not return an error", g)
+		}
+	}
+}
+
+func TestGaussianSample(t *testing.T) {
+	g := NewFromMeanAndStdDev(10, 3)
+	r := rand.New(rand.NewSource(1))
+
+	const n = 10000
+	var sum, sumSq float64
+	for i := 0; i < n; i++ {
+		x := g.Sample(r)
+		sum += x
+		sumSq += x * x
+	}
+	mean := sum / n
+	stdDev := math.Sqrt(sumSq/n - mean*mean)
+	if math.Abs(mean-10) > 0.1 || math.Abs(stdDev-3) > 0.1 {
+		t.Errorf("samples have mean %v and standard deviation %v, want 10 and 3", mean, stdDev)
+	}
+}
+
+func TestGaussianString(t *testing.T) {
+	tests := []struct {
+		g    Gaussian
+		want string
+	}{
+		{NewFromMeanAndStdDev(25, 25.0/3), "Gaussian(mean=25.000 stddev=8.333)"},
+		{NewFromPrecision(0, 0), "Gaussian(precisionMean=0 precision=0)"},
+	}
+	for _, tt := range tests {
+		if got := tt.g.String(); got != tt.want {
+			t.Errorf("String() == %q, want %q", got, tt.want)
+		}
+	}
+}
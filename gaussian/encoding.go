@@ -0,0 +1,123 @@
+package gaussian
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mafredri/go-trueskill/internal/textfield"
+)
+
+// Version and length of the binary encoding produced by MarshalBinary, the
+// version is stored as the first byte.
+const (
+	binaryVersion = 1
+	binaryLen     = 1 + 2*8
+)
+
+var (
+	errBinaryLength  = errors.New("invalid binary length")
+	errBinaryVersion = errors.New("unsupported binary version")
+)
+
+// MarshalText encodes the gaussian in the precision form as
+// "precisionMean=<float> precision=<float>". The encoding is lossless.
+func (a Gaussian) MarshalText() ([]byte, error) {
+	var w textfield.Writer
+	w.Float("precisionMean", a.PrecisionMean)
+	w.Float("precision", a.Precision)
+	return w.Bytes(), nil
+}
+
+// UnmarshalText decodes a gaussian encoded by MarshalText. Both fields must
+// be present exactly once.
+func (a *Gaussian) UnmarshalText(text []byte) error {
+	fields, err := textfield.Parse(text)
+	if err != nil {
+		return err
+	}
+	precisionMean, err := fields.Float("precisionMean")
+	if err != nil {
+		return err
+	}
+	precision, err := fields.Float("precision")
+	if err != nil {
+		return err
+	}
+	if err = fields.Unknown(); err != nil {
+		return err
+	}
+	*a = NewFromPrecision(precisionMean, precision)
+	return nil
+}
+
+type gaussianJSON struct {
+	PrecisionMean float64 `json:"precisionMean"`
+	Precision     float64 `json:"precision"`
+}
+
+// MarshalJSON encodes the gaussian as a JSON object with the fields
+// precisionMean and precision.
+func (a Gaussian) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gaussianJSON{a.PrecisionMean, a.Precision})
+}
+
+// UnmarshalJSON decodes a gaussian encoded by MarshalJSON.
+func (a *Gaussian) UnmarshalJSON(data []byte) error {
+	var g gaussianJSON
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*a = NewFromPrecision(g.PrecisionMean, g.Precision)
+	return nil
+}
+
+// MarshalBinary encodes the gaussian into a compact, versioned binary form: a
+// version byte followed by the precision adjusted mean and the precision as
+// big-endian IEEE 754 floats.
+func (a Gaussian) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, binaryLen)
+	buf[0] = binaryVersion
+	binary.BigEndian.PutUint64(buf[1:], math.Float64bits(a.PrecisionMean))
+	binary.BigEndian.PutUint64(buf[9:], math.Float64bits(a.Precision))
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a gaussian encoded by MarshalBinary.
+func (a *Gaussian) UnmarshalBinary(data []byte) error {
+	if len(data) != binaryLen {
+		return errBinaryLength
+	}
+	if data[0] != binaryVersion {
+		return errBinaryVersion
+	}
+	*a = NewFromPrecision(
+		math.Float64frombits(binary.BigEndian.Uint64(data[1:])),
+		math.Float64frombits(binary.BigEndian.Uint64(data[9:])),
+	)
+	return nil
+}
+
+// Value implements driver.Valuer, the gaussian is stored in its text form.
+func (a Gaussian) Value() (driver.Value, error) {
+	text, err := a.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner for values stored by Value.
+func (a *Gaussian) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return a.UnmarshalText([]byte(src))
+	case []byte:
+		return a.UnmarshalText(src)
+	default:
+		return fmt.Errorf("cannot scan %T", src)
+	}
+}
@@ -0,0 +1,81 @@
+package gaussian
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGaussianMarshalRoundTrip(t *testing.T) {
+	g := NewFromPrecision(0.36, 0.0144)
+
+	text, err := g.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantText := "precisionMean=0.36 precision=0.0144"
+	if string(text) != wantText {
+		t.Errorf("MarshalText() == %q, want %q", text, wantText)
+	}
+
+	var fromText Gaussian
+	if err = fromText.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !fromText.Equals(g) {
+		t.Errorf("UnmarshalText(%q) == %#v, want %#v", text, fromText, g)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Gaussian
+	if err = json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !fromJSON.Equals(g) {
+		t.Errorf("json.Unmarshal(%s) == %#v, want %#v", data, fromJSON, g)
+	}
+
+	bin, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBinary Gaussian
+	if err = fromBinary.UnmarshalBinary(bin); err != nil {
+		t.Fatal(err)
+	}
+	if !fromBinary.Equals(g) {
+		t.Errorf("UnmarshalBinary(%x) == %#v, want %#v", bin, fromBinary, g)
+	}
+
+	v, err := g.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned Gaussian
+	if err = scanned.Scan([]byte(v.(string))); err != nil {
+		t.Fatal(err)
+	}
+	if !scanned.Equals(g) {
+		t.Errorf("Scan(%q) == %#v, want %#v", v, scanned, g)
+	}
+}
+
+func TestGaussianUnmarshalErrors(t *testing.T) {
+	var g Gaussian
+	for _, text := range []string{"", "precision=1", "precisionMean=1 precision=x", "mu=1 sigma=2",
+		"precision=1 precision=2", "precisionMean=1 precisionMean=2", "precisionMean=1 precision=2 precision=3"} {
+		if err := g.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) did not return an error", text)
+		}
+	}
+	for _, data := range [][]byte{nil, {2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := g.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary(%x) did not return an error", data)
+		}
+	}
+	if err := g.Scan(1); err == nil {
+		t.Error("Scan(1) did not return an error")
+	}
+}
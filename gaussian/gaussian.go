@@ -2,17 +2,28 @@
 package gaussian
 
 import (
+	"errors"
 	"math"
 
 	"github.com/mafredri/go-trueskill/mathextra"
 )
 
 // Gaussian represents a gaussian based on a precision and a precision adjusted mean.
+//
+// Gaussian implements the text, JSON, binary and database/sql encodings. A
+// struct that embeds Gaussian promotes these methods and is encoded as the
+// gaussian alone, its other fields are dropped. Use a named field instead of
+// embedding to encode the struct field by field.
 type Gaussian struct {
 	PrecisionMean float64 // PrecisionMean (pi, π = μ/σ^2) is the precision adjusted mean.
 	Precision     float64 // Precision (tau, τ = 1/σ2) is the inverse of the variance.
 }
 
+var (
+	errImproper  = errors.New("gaussian is improper, precision must be positive")
+	errNotFinite = errors.New("gaussian is not finite")
+)
+
 // NewFromMeanAndStdDev create a new gaussian from the mean and standard deviation.
 func NewFromMeanAndStdDev(mean, stdDev float64) Gaussian {
 	variance := stdDev * stdDev
@@ -44,6 +55,79 @@ func (a Gaussian) StdDev() float64 {
 	return math.Sqrt(a.Variance())
 }
 
+// IsFinite reports whether both the precision adjusted mean and the precision
+// are finite numbers.
+func (a Gaussian) IsFinite() bool {
+	return isFinite(a.PrecisionMean) && isFinite(a.Precision)
+}
+
+// IsProper reports whether the gaussian is a proper distribution, it must be
+// finite and have a positive precision. The uniform gaussian (zero precision)
+// used as the initial marginal and message in a factor graph is improper, as
+// is a gaussian with a negative precision produced by Div.
+func (a Gaussian) IsProper() bool {
+	return a.IsFinite() && a.Precision > 0
+}
+
+// Check returns an error if the gaussian is not finite or not proper.
+func (a Gaussian) Check() error {
+	switch {
+	case !a.IsFinite():
+		return errNotFinite
+	case a.Precision <= 0:
+		return errImproper
+	}
+	return nil
+}
+
+// MeanChecked works like Mean but returns an error instead of NaN or an
+// infinity when the gaussian is improper.
+func (a Gaussian) MeanChecked() (float64, error) {
+	if err := a.Check(); err != nil {
+		return 0, err
+	}
+	mean := a.Mean()
+	if !isFinite(mean) {
+		return 0, errNotFinite
+	}
+	return mean, nil
+}
+
+// VarianceChecked works like Variance but returns an error instead of an
+// infinite or negative variance when the gaussian is improper.
+func (a Gaussian) VarianceChecked() (float64, error) {
+	if err := a.Check(); err != nil {
+		return 0, err
+	}
+	variance := a.Variance()
+	if !isFinite(variance) {
+		return 0, errNotFinite
+	}
+	return variance, nil
+}
+
+// StdDevChecked works like StdDev but returns an error instead of NaN or an
+// infinity when the gaussian is improper.
+func (a Gaussian) StdDevChecked() (float64, error) {
+	variance, err := a.VarianceChecked()
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(variance), nil
+}
+
+// ClampPrecision returns the uniform gaussian (zero precision) if the
+// precision is negative or NaN, otherwise the gaussian is returned as is.
+// Dividing a marginal by a message can leave a negative precision during
+// expectation propagation, clamping it keeps the result usable as a cavity
+// distribution.
+func (a Gaussian) ClampPrecision() Gaussian {
+	if !(a.Precision >= 0) {
+		return NewFromPrecision(0, 0)
+	}
+	return a
+}
+
 // Mul multiplies two gaussians by adding their precision adjusted means and precisions.
 func (a Gaussian) Mul(b Gaussian) Gaussian {
 	return NewFromPrecision(a.PrecisionMean+b.PrecisionMean, a.Precision+b.Precision)
@@ -98,3 +182,7 @@ func LogRatioNorm(a, b Gaussian) float64 {
 	meanDiff := a.Mean() - b.Mean()
 	return math.Log(bVar) + mathextra.LogSqrt2Pi - math.Log(varDiff)/2.0 + meanDiff*meanDiff/(2.0*varDiff)
 }
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
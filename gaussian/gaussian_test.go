@@ -271,3 +271,73 @@ func TestLogRatioNorm1234(t *testing.T) {
 		t.Errorf("LogProdNorm(a, a) == %.13f, want %.13f", logZ, want)
 	}
 }
+
+func TestGaussianIsProper(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      Gaussian
+		proper bool
+		finite bool
+	}{
+		{"Normal", NewFromMeanAndStdDev(0, 1), true, true},
+		{"Uniform", NewFromPrecision(0, 0), false, true},
+		{"Negative", NewFromPrecision(1, -2), false, true},
+		{"NaN", NewFromPrecision(math.NaN(), 1), false, false},
+		{"Inf", NewFromPrecision(0, math.Inf(1)), false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.g.IsProper(); got != tt.proper {
+			t.Errorf("%s: IsProper() == %v, want %v", tt.name, got, tt.proper)
+		}
+		if got := tt.g.IsFinite(); got != tt.finite {
+			t.Errorf("%s: IsFinite() == %v, want %v", tt.name, got, tt.finite)
+		}
+		if err := tt.g.Check(); (err == nil) != tt.proper {
+			t.Errorf("%s: Check() == %v, want error %v", tt.name, err, !tt.proper)
+		}
+		if _, err := tt.g.MeanChecked(); (err == nil) != tt.proper {
+			t.Errorf("%s: MeanChecked() error == %v, want error %v", tt.name, err, !tt.proper)
+		}
+		if _, err := tt.g.StdDevChecked(); (err == nil) != tt.proper {
+			t.Errorf("%s: StdDevChecked() error == %v, want error %v", tt.name, err, !tt.proper)
+		}
+	}
+}
+
+func TestGaussianCheckedAccessors(t *testing.T) {
+	g := NewFromMeanAndStdDev(25, 5)
+
+	mean, err := g.MeanChecked()
+	if err != nil || !mathextra.Float64AlmostEq(mean, 25, epsilon) {
+		t.Errorf("MeanChecked() == %v, %v, want 25", mean, err)
+	}
+	variance, err := g.VarianceChecked()
+	if err != nil || !mathextra.Float64AlmostEq(variance, 25, epsilon) {
+		t.Errorf("VarianceChecked() == %v, %v, want 25", variance, err)
+	}
+	stdDev, err := g.StdDevChecked()
+	if err != nil || !mathextra.Float64AlmostEq(stdDev, 5, epsilon) {
+		t.Errorf("StdDevChecked() == %v, %v, want 5", stdDev, err)
+	}
+
+	// The mean overflows even though the gaussian itself is finite.
+	if _, err := NewFromPrecision(math.MaxFloat64, 0.5).MeanChecked(); err == nil {
+		t.Error("MeanChecked() of an overflowing mean did not return an error")
+	}
+}
+
+func TestGaussianClampPrecision(t *testing.T) {
+	tests := []struct {
+		g, want Gaussian
+	}{
+		{NewFromPrecision(1, 2), NewFromPrecision(1, 2)},
+		{NewFromPrecision(1, 0), NewFromPrecision(1, 0)},
+		{NewFromPrecision(1, -2), NewFromPrecision(0, 0)},
+		{NewFromPrecision(1, math.NaN()), NewFromPrecision(0, 0)},
+	}
+	for _, tt := range tests {
+		if got := tt.g.ClampPrecision(); !got.Equals(tt.want) {
+			t.Errorf("%#v.ClampPrecision() == %#v, want %#v", tt.g, got, tt.want)
+		}
+	}
+}
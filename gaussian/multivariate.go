@@ -0,0 +1,400 @@
+package gaussian
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+var (
+	errDimensionMismatch = errors.New("dimensions of the multivariate gaussian do not match")
+	errInvalidIndex      = errors.New("index out of range or duplicate")
+	errNotSymmetric      = errors.New("covariance matrix is not symmetric")
+)
+
+// symmetryTolerance is the relative difference allowed between the mirrored
+// entries of a symmetric matrix, to accept matrices computed in floating
+// point.
+const symmetryTolerance = 1e-9
+
+// Multivariate is a multivariate gaussian in moment form, represented by a
+// mean vector and a covariance matrix in row-major order.
+type Multivariate struct {
+	Mean       []float64   // Mean (mu, μ) of every dimension.
+	Covariance [][]float64 // Covariance (Σ), symmetric positive definite.
+}
+
+// MultivariateCanonical is a multivariate gaussian in canonical form, the
+// multivariate counterpart of Gaussian. It is represented by a precision
+// adjusted mean vector and a precision matrix in row-major order.
+type MultivariateCanonical struct {
+	PrecisionMean []float64   // PrecisionMean (Λμ) is the precision adjusted mean.
+	Precision     [][]float64 // Precision (Λ = Σ^-1) is the inverse of the covariance.
+}
+
+// NewMultivariate creates a new multivariate gaussian from the mean vector
+// and the covariance matrix. The arguments are copied. An error is returned if
+// the dimensions do not match or the covariance matrix is not symmetric
+// positive definite.
+func NewMultivariate(mean []float64, covariance [][]float64) (Multivariate, error) {
+	m := Multivariate{
+		Mean:       append([]float64(nil), mean...),
+		Covariance: copyMatrix(covariance),
+	}
+	if _, err := m.cholesky(); err != nil {
+		return Multivariate{}, err
+	}
+	if !isSymmetric(m.Covariance) {
+		return Multivariate{}, errNotSymmetric
+	}
+	return m, nil
+}
+
+// NewMultivariateIndependent creates a multivariate gaussian where every
+// dimension is one of the independent gaussians gs. Every gaussian must be
+// proper.
+func NewMultivariateIndependent(gs ...Gaussian) Multivariate {
+	m := Multivariate{
+		Mean:       make([]float64, len(gs)),
+		Covariance: newMatrix(len(gs)),
+	}
+	for i, g := range gs {
+		m.Mean[i] = g.Mean()
+		m.Covariance[i][i] = g.Variance()
+	}
+	return m
+}
+
+// NewMultivariateFromPrecision creates a new multivariate gaussian in
+// canonical form from the precision adjusted mean vector and the precision
+// matrix. The arguments are copied. An error is returned if the dimensions do
+// not match.
+func NewMultivariateFromPrecision(precisionMean []float64, precision [][]float64) (MultivariateCanonical, error) {
+	c := MultivariateCanonical{
+		PrecisionMean: append([]float64(nil), precisionMean...),
+		Precision:     copyMatrix(precision),
+	}
+	if !isSquare(c.Precision, len(c.PrecisionMean)) {
+		return MultivariateCanonical{}, errDimensionMismatch
+	}
+	return c, nil
+}
+
+// Dim returns the number of dimensions.
+func (m Multivariate) Dim() int {
+	return len(m.Mean)
+}
+
+func (m Multivariate) cholesky() (mathextra.Cholesky, error) {
+	if !isSquare(m.Covariance, len(m.Mean)) {
+		return mathextra.Cholesky{}, errDimensionMismatch
+	}
+	return mathextra.NewCholesky(m.Covariance)
+}
+
+// Canonical returns the multivariate gaussian in canonical form. An error is
+// returned if the covariance matrix is not positive definite.
+func (m Multivariate) Canonical() (MultivariateCanonical, error) {
+	chol, err := m.cholesky()
+	if err != nil {
+		return MultivariateCanonical{}, err
+	}
+	return MultivariateCanonical{
+		PrecisionMean: chol.Solve(m.Mean),
+		Precision:     chol.Inverse(),
+	}, nil
+}
+
+// Marginal returns the marginal distribution of dimension i.
+func (m Multivariate) Marginal(i int) Gaussian {
+	return NewFromMeanAndVariance(m.Mean[i], m.Covariance[i][i])
+}
+
+// Marginalize returns the marginal distribution of the dimensions idx, in
+// the order given, by integrating out all other dimensions.
+func (m Multivariate) Marginalize(idx ...int) (Multivariate, error) {
+	if !validIndex(idx, m.Dim()) {
+		return Multivariate{}, errInvalidIndex
+	}
+	r := Multivariate{
+		Mean:       make([]float64, len(idx)),
+		Covariance: newMatrix(len(idx)),
+	}
+	for i, a := range idx {
+		r.Mean[i] = m.Mean[a]
+		for j, b := range idx {
+			r.Covariance[i][j] = m.Covariance[a][b]
+		}
+	}
+	return r, nil
+}
+
+// Condition returns the distribution of the remaining dimensions, in their
+// original order, given that dimension idx[i] is observed as values[i].
+func (m Multivariate) Condition(idx []int, values []float64) (Multivariate, error) {
+	if len(idx) != len(values) {
+		return Multivariate{}, errDimensionMismatch
+	}
+	if !validIndex(idx, m.Dim()) {
+		return Multivariate{}, errInvalidIndex
+	}
+
+	observed, err := m.Marginalize(idx...)
+	if err != nil {
+		return Multivariate{}, err
+	}
+	chol, err := observed.cholesky()
+	if err != nil {
+		return Multivariate{}, err
+	}
+
+	var rest []int
+	for i := 0; i < m.Dim(); i++ {
+		if !containsIndex(idx, i) {
+			rest = append(rest, i)
+		}
+	}
+	r, err := m.Marginalize(rest...)
+	if err != nil {
+		return Multivariate{}, err
+	}
+
+	// μ_a|b = μ_a + Σ_ab Σ_bb^-1 (x_b - μ_b)
+	// Σ_a|b = Σ_aa - Σ_ab Σ_bb^-1 Σ_ba
+	diff := make([]float64, len(idx))
+	for j := range idx {
+		diff[j] = values[j] - observed.Mean[j]
+	}
+	w := chol.Solve(diff)
+	gains := make([][]float64, len(rest)) // Σ_bb^-1 Σ_ba, one row per remaining dimension
+	for i, a := range rest {
+		cross := make([]float64, len(idx))
+		for j, b := range idx {
+			cross[j] = m.Covariance[a][b]
+		}
+		r.Mean[i] += dot(cross, w)
+		gains[i] = chol.Solve(cross)
+	}
+	for i, a := range rest {
+		for k := range rest {
+			for j, b := range idx {
+				r.Covariance[i][k] -= m.Covariance[a][b] * gains[k][j]
+			}
+		}
+	}
+	return r, nil
+}
+
+// Transform returns the distribution of A*x + b, where x is distributed by
+// m. The matrix a has one row per dimension of the result and one column per
+// dimension of m. A nil b is treated as the zero vector.
+func (m Multivariate) Transform(a [][]float64, b []float64) (Multivariate, error) {
+	n := m.Dim()
+	if b != nil && len(b) != len(a) {
+		return Multivariate{}, errDimensionMismatch
+	}
+	for _, row := range a {
+		if len(row) != n {
+			return Multivariate{}, errDimensionMismatch
+		}
+	}
+
+	r := Multivariate{
+		Mean:       make([]float64, len(a)),
+		Covariance: newMatrix(len(a)),
+	}
+	aCov := make([][]float64, len(a)) // A*Σ
+	for i, row := range a {
+		r.Mean[i] = dot(row, m.Mean)
+		if b != nil {
+			r.Mean[i] += b[i]
+		}
+		aCov[i] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			for j := 0; j < n; j++ {
+				aCov[i][k] += row[j] * m.Covariance[j][k]
+			}
+		}
+	}
+	for i := range a {
+		for j, row := range a {
+			r.Covariance[i][j] = dot(aCov[i], row)
+		}
+	}
+	return r, nil
+}
+
+// Mul multiplies two multivariate gaussians of the same dimension, see
+// MultivariateCanonical.Mul. The result is normalized.
+func (m Multivariate) Mul(n Multivariate) (Multivariate, error) {
+	a, err := m.Canonical()
+	if err != nil {
+		return Multivariate{}, err
+	}
+	b, err := n.Canonical()
+	if err != nil {
+		return Multivariate{}, err
+	}
+	c, err := a.Mul(b)
+	if err != nil {
+		return Multivariate{}, err
+	}
+	return c.Moment()
+}
+
+// LogPdf returns the natural logarithm of the probability density at x.
+func (m Multivariate) LogPdf(x []float64) (float64, error) {
+	if len(x) != m.Dim() {
+		return 0, errDimensionMismatch
+	}
+	chol, err := m.cholesky()
+	if err != nil {
+		return 0, err
+	}
+	diff := make([]float64, len(x))
+	for i := range x {
+		diff[i] = x[i] - m.Mean[i]
+	}
+	return -float64(len(x))*mathextra.LogSqrt2Pi - chol.LogDet()/2 - dot(diff, chol.Solve(diff))/2, nil
+}
+
+// Sample returns a random vector drawn from the distribution using r.
+func (m Multivariate) Sample(r *rand.Rand) ([]float64, error) {
+	chol, err := m.cholesky()
+	if err != nil {
+		return nil, err
+	}
+	z := make([]float64, m.Dim())
+	for i := range z {
+		z[i] = r.NormFloat64()
+	}
+	l := chol.L()
+	x := append([]float64(nil), m.Mean...)
+	for i := range x {
+		for j := 0; j <= i; j++ {
+			x[i] += l[i][j] * z[j]
+		}
+	}
+	return x, nil
+}
+
+// Dim returns the number of dimensions.
+func (c MultivariateCanonical) Dim() int {
+	return len(c.PrecisionMean)
+}
+
+// Moment returns the multivariate gaussian in moment form. An error is
+// returned if the precision matrix is not positive definite, i.e. the
+// distribution is improper.
+func (c MultivariateCanonical) Moment() (Multivariate, error) {
+	if !isSquare(c.Precision, c.Dim()) {
+		return Multivariate{}, errDimensionMismatch
+	}
+	chol, err := mathextra.NewCholesky(c.Precision)
+	if err != nil {
+		return Multivariate{}, err
+	}
+	return Multivariate{
+		Mean:       chol.Solve(c.PrecisionMean),
+		Covariance: chol.Inverse(),
+	}, nil
+}
+
+// Mul multiplies two multivariate gaussians by adding their precision
+// adjusted means and precisions.
+func (c MultivariateCanonical) Mul(d MultivariateCanonical) (MultivariateCanonical, error) {
+	return c.combine(d, 1)
+}
+
+// Div divides two multivariate gaussians by subtracting the precision
+// adjusted means and precisions.
+func (c MultivariateCanonical) Div(d MultivariateCanonical) (MultivariateCanonical, error) {
+	return c.combine(d, -1)
+}
+
+func (c MultivariateCanonical) combine(d MultivariateCanonical, sign float64) (MultivariateCanonical, error) {
+	n := c.Dim()
+	if d.Dim() != n || !isSquare(c.Precision, n) || !isSquare(d.Precision, n) {
+		return MultivariateCanonical{}, errDimensionMismatch
+	}
+	r := MultivariateCanonical{
+		PrecisionMean: make([]float64, n),
+		Precision:     newMatrix(n),
+	}
+	for i := 0; i < n; i++ {
+		r.PrecisionMean[i] = c.PrecisionMean[i] + sign*d.PrecisionMean[i]
+		for j := 0; j < n; j++ {
+			r.Precision[i][j] = c.Precision[i][j] + sign*d.Precision[i][j]
+		}
+	}
+	return r, nil
+}
+
+func newMatrix(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+	return m
+}
+
+func copyMatrix(a [][]float64) [][]float64 {
+	m := make([][]float64, len(a))
+	for i := range a {
+		m[i] = append([]float64(nil), a[i]...)
+	}
+	return m
+}
+
+func isSquare(a [][]float64, n int) bool {
+	if len(a) != n {
+		return false
+	}
+	for _, row := range a {
+		if len(row) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// isSymmetric reports whether the square matrix a is symmetric within
+// symmetryTolerance.
+func isSymmetric(a [][]float64) bool {
+	for i := range a {
+		for j := 0; j < i; j++ {
+			if !mathextra.Float64AlmostEq(a[i][j], a[j][i], symmetryTolerance*(math.Abs(a[i][j])+math.Abs(a[j][i]))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func validIndex(idx []int, n int) bool {
+	for i, a := range idx {
+		if a < 0 || a >= n || containsIndex(idx[:i], a) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsIndex(idx []int, i int) bool {
+	for _, a := range idx {
+		if a == i {
+			return true
+		}
+	}
+	return false
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
@@ -0,0 +1,210 @@
+package gaussian
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func newTestMultivariate(t *testing.T) Multivariate {
+	m, err := NewMultivariate([]float64{1, 2, 3}, [][]float64{
+		{4, 2, 0},
+		{2, 3, 1},
+		{0, 1, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func multivariateAlmostEq(a, b Multivariate, eps float64) bool {
+	if a.Dim() != b.Dim() {
+		return false
+	}
+	for i := range a.Mean {
+		if !mathextra.Float64AlmostEq(a.Mean[i], b.Mean[i], eps) {
+			return false
+		}
+		for j := range a.Mean {
+			if !mathextra.Float64AlmostEq(a.Covariance[i][j], b.Covariance[i][j], eps) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestNewMultivariateErrors(t *testing.T) {
+	if _, err := NewMultivariate([]float64{0, 0}, [][]float64{{1, 2}, {2, 1}}); err == nil {
+		t.Error("NewMultivariate() with an indefinite covariance did not return an error")
+	}
+	if _, err := NewMultivariate([]float64{0, 0}, [][]float64{{2, 1}, {0.5, 2}}); err == nil {
+		t.Error("NewMultivariate() with an asymmetric covariance did not return an error")
+	}
+	if _, err := NewMultivariate([]float64{0, 0}, [][]float64{{2, 1}, {1 + 1e-12, 2}}); err != nil {
+		t.Errorf("NewMultivariate() with a covariance symmetric within rounding returned %v", err)
+	}
+	if _, err := NewMultivariate([]float64{0, 0}, [][]float64{{1}}); err == nil {
+		t.Error("NewMultivariate() with mismatched dimensions did not return an error")
+	}
+	if _, err := NewMultivariateFromPrecision([]float64{0}, [][]float64{{1, 0}}); err == nil {
+		t.Error("NewMultivariateFromPrecision() with mismatched dimensions did not return an error")
+	}
+}
+
+func TestMultivariateCanonical(t *testing.T) {
+	m := newTestMultivariate(t)
+
+	c, err := m.Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Moment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !multivariateAlmostEq(got, m, 1e-12) {
+		t.Errorf("Canonical().Moment() == %v, want %v", got, m)
+	}
+}
+
+func TestMultivariateMarginalize(t *testing.T) {
+	m := newTestMultivariate(t)
+
+	if got, want := m.Marginal(1), NewFromMeanAndVariance(2, 3); !got.Equals(want) {
+		t.Errorf("Marginal(1) == %#v, want %#v", got, want)
+	}
+
+	got, err := m.Marginalize(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Multivariate{Mean: []float64{3, 1}, Covariance: [][]float64{{2, 0}, {0, 4}}}
+	if !multivariateAlmostEq(got, want, epsilon) {
+		t.Errorf("Marginalize(2, 0) == %v, want %v", got, want)
+	}
+
+	if _, err := m.Marginalize(0, 0); err == nil {
+		t.Error("Marginalize(0, 0) did not return an error")
+	}
+	if _, err := m.Marginalize(3); err == nil {
+		t.Error("Marginalize(3) did not return an error")
+	}
+}
+
+func TestMultivariateCondition(t *testing.T) {
+	m := newTestMultivariate(t)
+
+	got, err := m.Condition([]int{2}, []float64{5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Multivariate{Mean: []float64{1, 3}, Covariance: [][]float64{{4, 2}, {2, 2.5}}}
+	if !multivariateAlmostEq(got, want, 1e-12) {
+		t.Errorf("Condition([2], [5]) == %v, want %v", got, want)
+	}
+
+	// Conditioning on every dimension but one is the same as conditioning
+	// one at a time.
+	step, err := got.Condition([]int{1}, []float64{4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, err := m.Condition([]int{1, 2}, []float64{4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !multivariateAlmostEq(both, step, 1e-12) {
+		t.Errorf("Condition([1, 2], [4, 5]) == %v, want %v", both, step)
+	}
+}
+
+func TestMultivariateTransform(t *testing.T) {
+	m := newTestMultivariate(t)
+
+	got, err := m.Transform([][]float64{{1, -1, 0}, {0, 0, 2}}, []float64{0.5, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Var(x0-x1) = 4+3-2*2, Var(2*x2) = 4*2, Cov(x0-x1, 2*x2) = 2*(0-1).
+	want := Multivariate{Mean: []float64{-0.5, 6}, Covariance: [][]float64{{3, -2}, {-2, 8}}}
+	if !multivariateAlmostEq(got, want, epsilon) {
+		t.Errorf("Transform() == %v, want %v", got, want)
+	}
+}
+
+func TestMultivariateMulIndependent(t *testing.T) {
+	a := []Gaussian{NewFromMeanAndVariance(1, 2), NewFromMeanAndVariance(-3, 0.5)}
+	b := []Gaussian{NewFromMeanAndVariance(4, 1), NewFromMeanAndVariance(2, 4)}
+
+	got, err := NewMultivariateIndependent(a...).Mul(NewMultivariateIndependent(b...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewMultivariateIndependent(a[0].Mul(b[0]), a[1].Mul(b[1]))
+	if !multivariateAlmostEq(got, want, 1e-12) {
+		t.Errorf("Mul() == %v, want %v", got, want)
+	}
+
+	ca, _ := NewMultivariateIndependent(a...).Canonical()
+	cb, _ := NewMultivariateIndependent(b...).Canonical()
+	prod, _ := ca.Mul(cb)
+	quot, err := prod.Div(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back, err := quot.Moment(); err != nil || !multivariateAlmostEq(back, NewMultivariateIndependent(a...), 1e-12) {
+		t.Errorf("Mul(b).Div(b) == %v, %v, want %v", back, err, NewMultivariateIndependent(a...))
+	}
+}
+
+func TestMultivariateLogPdf(t *testing.T) {
+	a := NewFromMeanAndVariance(1, 2)
+	b := NewFromMeanAndVariance(-3, 0.5)
+	m := NewMultivariateIndependent(a, b)
+
+	got, err := m.LogPdf([]float64{0, -2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := a.LogPdf(0) + b.LogPdf(-2)
+	if !mathextra.Float64AlmostEq(got, want, 1e-12) {
+		t.Errorf("LogPdf() == %v, want %v", got, want)
+	}
+}
+
+func TestMultivariateSample(t *testing.T) {
+	m := newTestMultivariate(t)
+	r := rand.New(rand.NewSource(1))
+
+	const n = 20000
+	var sum [3]float64
+	var sumSq [3][3]float64
+	for k := 0; k < n; k++ {
+		x, err := m.Sample(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range x {
+			sum[i] += x[i]
+			for j := range x {
+				sumSq[i][j] += x[i] * x[j]
+			}
+		}
+	}
+	for i := range sum {
+		mean := sum[i] / n
+		if math.Abs(mean-m.Mean[i]) > 0.1 {
+			t.Errorf("sample mean %d == %v, want %v", i, mean, m.Mean[i])
+		}
+		for j := range sum {
+			cov := sumSq[i][j]/n - mean*sum[j]/n
+			if math.Abs(cov-m.Covariance[i][j]) > 0.2 {
+				t.Errorf("sample covariance %d,%d == %v, want %v", i, j, cov, m.Covariance[i][j])
+			}
+		}
+	}
+}
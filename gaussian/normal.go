@@ -16,7 +16,35 @@ func NormPdf(t float64) float64 {
 	return mathextra.InvSqrt2Pi * math.Exp(-(t * t / 2.0))
 }
 
+// MillsRatio returns the Mills ratio (1 - NormCdf(x)) / NormPdf(x). Unlike
+// the ratio it remains accurate when both the cdf and pdf underflow, the
+// ratio approaches 1/x as x grows.
+func MillsRatio(x float64) float64 {
+	return mathextra.MillsRatio(x)
+}
+
+// LogNormCdf returns the natural logarithm of the cumulative gaussian
+// distribution (cdf) at the point of interest. It remains accurate where the
+// cdf underflows or rounds to one.
+func LogNormCdf(t float64) float64 {
+	switch {
+	case t < -mathextra.MillsRatioDirect:
+		return -t*t/2 - mathextra.LogSqrt2Pi + math.Log(MillsRatio(-t))
+	case t < 0:
+		return math.Log(NormCdf(t))
+	default:
+		return math.Log1p(-NormCdf(-t))
+	}
+}
+
 // NormPpf returns the percent point function (ppf, the inverse of cdf) at the point of interest.
+// See mathextra.NormalQuantile for the accuracy and edge cases.
 func NormPpf(p float64) float64 {
-	return -math.Sqrt2 * mathextra.InvErfc(2.0*p)
+	return mathextra.NormalQuantile(p)
+}
+
+// NormPpfLog returns the percent point function at the probability exp(logP),
+// the inverse of LogNormCdf.
+func NormPpfLog(logP float64) float64 {
+	return mathextra.NormalQuantileLog(logP)
 }
@@ -1,6 +1,7 @@
 package gaussian
 
 import (
+	"math"
 	"testing"
 
 	"github.com/mafredri/go-trueskill/mathextra"
@@ -63,3 +64,66 @@ func TestNormPpfAlt(t *testing.T) {
 		t.Errorf("NormPpf(%f) == %.6f, want %.6f", x, r, want)
 	}
 }
+
+// Reference values computed with 1200 digit arithmetic.
+func TestLogNormCdf(t *testing.T) {
+	tests := []struct {
+		x, want float64
+	}{
+		{-1e4, -50000010.129278913},
+		{-100, -5005.5242086942053},
+		{-40, -804.6084420137538},
+		{-38, -726.5572160188201},
+		{-20, -203.91715537109727},
+		{-5, -15.064998393988725},
+		{-1, -1.8410216450092636},
+		{0, -0.69314718055994529},
+		{1, -0.17275377902344988},
+		{5, -2.8665161296376358e-07},
+		{20, -2.7536241186062337e-89},
+		{math.Inf(1), 0},
+	}
+	for _, tt := range tests {
+		got := LogNormCdf(tt.x)
+		if !mathextra.Float64AlmostEq(got/tt.want, 1, 1e-13) && got != tt.want {
+			t.Errorf("LogNormCdf(%g) == %.17g, want %.17g", tt.x, got, tt.want)
+		}
+	}
+	if got := LogNormCdf(math.Inf(-1)); !math.IsInf(got, -1) {
+		t.Errorf("LogNormCdf(-Inf) == %v, want -Inf", got)
+	}
+}
+
+// Reference values computed with 1200 digit arithmetic.
+func TestMillsRatio(t *testing.T) {
+	tests := []struct {
+		x, want float64
+	}{
+		{-1, 3.4770518117036944},
+		{0, 1.2533141373155003},
+		{1, 0.65567954241879844},
+		{5, 0.19280810471531576},
+		{20, 0.049875925981836787},
+		{40, 0.024984404205720571},
+		{100, 0.0099990002998501058},
+		{1e4, 9.9999999000000036e-05},
+	}
+	for _, tt := range tests {
+		got := MillsRatio(tt.x)
+		if !mathextra.Float64AlmostEq(got/tt.want, 1, 1e-14) {
+			t.Errorf("MillsRatio(%g) == %.17g, want %.17g", tt.x, got, tt.want)
+		}
+	}
+	if got := MillsRatio(math.Inf(1)); got != 0 {
+		t.Errorf("MillsRatio(+Inf) == %v, want 0", got)
+	}
+}
+
+func TestNormPpfLog(t *testing.T) {
+	for _, x := range []float64{-100, -20, -5, -1, 0.5, 3} {
+		got := NormPpfLog(LogNormCdf(x))
+		if !mathextra.Float64AlmostEq(got, x, 1e-12*math.Max(1, math.Abs(x))) {
+			t.Errorf("NormPpfLog(LogNormCdf(%g)) == %.17g, want %g", x, got, x)
+		}
+	}
+}
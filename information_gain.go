@@ -0,0 +1,48 @@
+package trueskill
+
+import "github.com/mafredri/go-trueskill/gaussian"
+
+// InformationGain is the expected reduction in uncertainty about the skills
+// of the players from playing a match. Every value is averaged over all
+// possible outcomes of the match, weighted by their predicted probability.
+type InformationGain struct {
+	ExpectedSigma  []float64 // Expected sigma of each player after the match
+	SigmaReduction []float64 // Current sigma minus the expected sigma
+	KL             []float64 // Expected KL divergence from prior to posterior
+	TotalKL        float64   // Sum of KL over all players
+}
+
+// ExpectedInformationGain returns how much a match between the players is
+// expected to reduce the uncertainty about their skills. Every possible
+// outcome of the match is rated and weighted by its predicted probability,
+// see PreviewOutcomes, so the number of players is limited to five.
+//
+// The KL divergence of a player is measured from its prior, the current skill
+// widened by the dynamics factor tau, to its posterior. The expected KL
+// divergence summed over all players is the expected information gained
+// from the match, in nats.
+func (ts Config) ExpectedInformationGain(players []Player) (InformationGain, error) {
+	previews, err := ts.PreviewOutcomes(players)
+	if err != nil {
+		return InformationGain{}, err
+	}
+
+	gain := InformationGain{
+		ExpectedSigma:  make([]float64, len(players)),
+		SigmaReduction: make([]float64, len(players)),
+		KL:             make([]float64, len(players)),
+	}
+
+	for i, p := range players {
+		prior := gaussian.NewFromMeanAndVariance(p.Mu(), p.Variance()+ts.tau*ts.tau)
+		for _, preview := range previews {
+			posterior := preview.Changes[i].After
+			gain.ExpectedSigma[i] += preview.Probability * posterior.Sigma()
+			gain.KL[i] += preview.Probability * posterior.Gaussian.KL(prior)
+		}
+		gain.SigmaReduction[i] = p.Sigma() - gain.ExpectedSigma[i]
+		gain.TotalKL += gain.KL[i]
+	}
+
+	return gain, nil
+}
@@ -0,0 +1,41 @@
+package trueskill
+
+import (
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestExpectedInformationGain(t *testing.T) {
+	ts := New()
+
+	newcomer := ts.NewPlayer()
+	veteran := NewPlayer(25, 1)
+	skilled := NewPlayer(40, 1)
+
+	even, err := ts.ExpectedInformationGain([]Player{newcomer, veteran})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lopsided, err := ts.ExpectedInformationGain([]Player{newcomer, skilled})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if even.SigmaReduction[0] <= 0 {
+		t.Errorf("SigmaReduction[0] == %v, want > 0", even.SigmaReduction[0])
+	}
+	if even.KL[0] <= even.KL[1] {
+		t.Errorf("newcomer KL %v not larger than veteran KL %v", even.KL[0], even.KL[1])
+	}
+	if even.TotalKL <= lopsided.TotalKL {
+		t.Errorf("even match TotalKL %v not larger than lopsided %v", even.TotalKL, lopsided.TotalKL)
+	}
+	if !mathextra.Float64AlmostEq(even.TotalKL, even.KL[0]+even.KL[1], 1e-12) {
+		t.Errorf("TotalKL == %v, want %v", even.TotalKL, even.KL[0]+even.KL[1])
+	}
+
+	if _, err = ts.ExpectedInformationGain([]Player{newcomer}); err == nil {
+		t.Error("ExpectedInformationGain() for one player did not return an error")
+	}
+}
@@ -0,0 +1,124 @@
+// Package textfield implements the space separated key=value text encoding
+// shared by the gaussian and trueskill packages.
+package textfield
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Writer writes space separated key=value pairs.
+type Writer struct {
+	bytes.Buffer
+}
+
+// Field writes a key=value pair, the value is written as is.
+func (w *Writer) Field(key, value string) {
+	if w.Len() > 0 {
+		w.WriteByte(' ')
+	}
+	w.WriteString(key)
+	w.WriteByte('=')
+	w.WriteString(value)
+}
+
+// Float writes f in the shortest form that parses back to the same value.
+func (w *Writer) Float(key string, f float64) {
+	w.Field(key, strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// Fields holds the key=value pairs parsed by Parse, fields are removed as
+// they are consumed so that unknown fields can be detected.
+type Fields map[string]string
+
+// Parse parses space separated key=value pairs, a value may be a quoted Go
+// string. An error is returned if a field is malformed or a key is repeated.
+func Parse(text []byte) (Fields, error) {
+	fields := make(Fields)
+	s := string(text)
+	for {
+		for len(s) > 0 && s[0] == ' ' {
+			s = s[1:]
+		}
+		if s == "" {
+			return fields, nil
+		}
+
+		eq := 0
+		for eq < len(s) && s[eq] != '=' && s[eq] != ' ' {
+			eq++
+		}
+		if eq == len(s) || s[eq] != '=' || eq == 0 {
+			return nil, fmt.Errorf("invalid field in %q", text)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if len(s) > 0 && s[0] == '"' {
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated quote in %q", text)
+			}
+			var err error
+			if value, err = strconv.Unquote(s[:end+1]); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", key, err)
+			}
+			s = s[end+1:]
+		} else {
+			end := 0
+			for end < len(s) && s[end] != ' ' {
+				end++
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+
+		if _, ok := fields[key]; ok {
+			return nil, fmt.Errorf("duplicate field %s", key)
+		}
+		fields[key] = value
+	}
+}
+
+// Has reports whether the field is present and not yet consumed.
+func (f Fields) Has(key string) bool {
+	_, ok := f[key]
+	return ok
+}
+
+// Take consumes the field and returns its value.
+func (f Fields) Take(key string) (string, bool) {
+	v, ok := f[key]
+	delete(f, key)
+	return v, ok
+}
+
+// Float consumes the field and parses its value, an error is returned if the
+// field is missing or invalid.
+func (f Fields) Float(key string) (float64, error) {
+	s, ok := f.Take(key)
+	if !ok {
+		return 0, fmt.Errorf("missing field %s", key)
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return v, nil
+}
+
+// Unknown returns an error if any field has not been consumed.
+func (f Fields) Unknown() error {
+	for key := range f {
+		return fmt.Errorf("unknown field %s", key)
+	}
+	return nil
+}
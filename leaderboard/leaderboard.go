@@ -0,0 +1,232 @@
+// Package leaderboard maintains players ordered by their skill and answers
+// rank queries without re-sorting the whole board after every update.
+package leaderboard
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+// ScoreFunc returns the score a player is ranked by, higher is better.
+type ScoreFunc func(p trueskill.Player) float64
+
+// Entry is a player on the leaderboard together with its score and rank.
+type Entry struct {
+	Player trueskill.IdentifiedPlayer
+	Score  float64
+	Rank   int // Position on the leaderboard, starting from one
+}
+
+// Leaderboard keeps players ordered by score, highest first. Players with
+// equal scores are ordered by ID. Insert, update, remove and rank queries
+// take O(log n) time.
+//
+// A Leaderboard is safe for concurrent use.
+type Leaderboard struct {
+	score ScoreFunc
+
+	mu    sync.RWMutex
+	root  *node
+	nodes map[string]*node
+	rnd   *rand.Rand
+}
+
+// New returns an empty leaderboard that ranks players by their conservative
+// TrueSkill, see trueskill.Config.TrueSkill.
+func New(ts trueskill.Config) *Leaderboard {
+	return NewWithScore(ts.TrueSkill)
+}
+
+// NewWithScore returns an empty leaderboard that ranks players by the
+// provided score function.
+func NewWithScore(score ScoreFunc) *Leaderboard {
+	return &Leaderboard{
+		score: score,
+		nodes: make(map[string]*node),
+		rnd:   rand.New(rand.NewSource(1)),
+	}
+}
+
+// Len returns the number of players on the leaderboard.
+func (lb *Leaderboard) Len() int {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return lb.root.len()
+}
+
+// Update inserts the player or, if a player with the same ID is already on
+// the leaderboard, replaces it and moves it to its new position.
+func (lb *Leaderboard) Update(players ...trueskill.IdentifiedPlayer) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	for _, p := range players {
+		lb.remove(p.ID)
+
+		n := &node{
+			player:   p,
+			score:    lb.scoreOf(p.Player),
+			priority: lb.rnd.Int63(),
+			size:     1,
+		}
+		left, right := split(lb.root, n)
+		lb.root = merge(merge(left, n), right)
+		lb.nodes[p.ID] = n
+	}
+}
+
+// Remove removes the player with the provided ID from the leaderboard and
+// reports whether it was present.
+func (lb *Leaderboard) Remove(id string) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	return lb.remove(id)
+}
+
+// Get returns the entry for the player with the provided ID.
+func (lb *Leaderboard) Get(id string) (Entry, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	n, ok := lb.nodes[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return n.entry(lb.rank(n)), true
+}
+
+// Rank returns the position of the player with the provided ID, starting
+// from one.
+func (lb *Leaderboard) Rank(id string) (int, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	n, ok := lb.nodes[id]
+	if !ok {
+		return 0, false
+	}
+	return lb.rank(n), true
+}
+
+// Top returns the n highest ranked players.
+func (lb *Leaderboard) Top(n int) []Entry {
+	return lb.Page(1, n)
+}
+
+// Page returns at most n entries starting from the provided rank.
+func (lb *Leaderboard) Page(rank, n int) []Entry {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return lb.page(rank, n)
+}
+
+// Around returns the page of entries surrounding the player with the
+// provided ID: up to before entries ranked above the player, the player
+// itself and up to after entries ranked below it.
+func (lb *Leaderboard) Around(id string, before, after int) ([]Entry, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	n, ok := lb.nodes[id]
+	if !ok {
+		return nil, false
+	}
+	rank := lb.rank(n)
+	first := rank - before
+	if first < 1 {
+		first = 1
+	}
+	return lb.page(first, rank+after-first+1), true
+}
+
+// Percentile returns the percentage of the other players on the leaderboard
+// that are ranked below the player with the provided ID. The highest ranked
+// player is in the 100th percentile, the lowest ranked in the 0th.
+func (lb *Leaderboard) Percentile(id string) (float64, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	n, ok := lb.nodes[id]
+	if !ok {
+		return 0, false
+	}
+	total := lb.root.len()
+	if total == 1 {
+		return 100, true
+	}
+	return 100 * float64(total-lb.rank(n)) / float64(total-1), true
+}
+
+// AtPercentile returns the entry closest to the provided percentile (between
+// 0 and 100), see Percentile.
+func (lb *Leaderboard) AtPercentile(percentile float64) (Entry, bool) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	total := lb.root.len()
+	if total == 0 || math.IsNaN(percentile) {
+		return Entry{}, false
+	}
+	percentile = math.Max(0, math.Min(100, percentile))
+	rank := total - int(math.Floor(percentile/100*float64(total-1)+0.5))
+	return lb.root.at(rank - 1).entry(rank), true
+}
+
+func (lb *Leaderboard) scoreOf(p trueskill.Player) float64 {
+	score := lb.score(p)
+	if math.IsNaN(score) {
+		// Keep the ordering total, NaN scores are ranked last.
+		return math.Inf(-1)
+	}
+	return score
+}
+
+func (lb *Leaderboard) remove(id string) bool {
+	n, ok := lb.nodes[id]
+	if !ok {
+		return false
+	}
+	left, right := split(lb.root, n)
+	_, right = splitAt(right, 1)
+	lb.root = merge(left, right)
+	delete(lb.nodes, id)
+	return true
+}
+
+// rank returns the one-based position of n.
+func (lb *Leaderboard) rank(n *node) int {
+	rank := 1
+	for cur := lb.root; cur != n; {
+		if n.less(cur) {
+			cur = cur.left
+		} else {
+			rank += cur.left.len() + 1
+			cur = cur.right
+		}
+	}
+	return rank + n.left.len()
+}
+
+func (lb *Leaderboard) page(rank, n int) []Entry {
+	if rank < 1 {
+		n += rank - 1
+		rank = 1
+	}
+	if total := lb.root.len(); rank+n-1 > total {
+		n = total - rank + 1
+	}
+	if n <= 0 {
+		return nil
+	}
+	entries := make([]Entry, 0, n)
+	lb.root.walk(rank-1, rank-1+n, func(nd *node, i int) {
+		entries = append(entries, nd.entry(i+1))
+	})
+	return entries
+}
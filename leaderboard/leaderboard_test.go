@@ -0,0 +1,155 @@
+package leaderboard
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+func testPlayer(id int, mu float64) trueskill.IdentifiedPlayer {
+	return trueskill.NewIdentifiedPlayer(fmt.Sprintf("p%04d", id), trueskill.NewPlayer(mu, 1))
+}
+
+func muScore(p trueskill.Player) float64 { return p.Mu() }
+
+// sorted returns the players ordered like the leaderboard orders them.
+func sorted(players map[string]trueskill.IdentifiedPlayer) []trueskill.IdentifiedPlayer {
+	var list []trueskill.IdentifiedPlayer
+	for _, p := range players {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Mu() != list[j].Mu() {
+			return list[i].Mu() > list[j].Mu()
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+func TestLeaderboardMatchesSort(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	lb := NewWithScore(muScore)
+	players := make(map[string]trueskill.IdentifiedPlayer)
+
+	for i := 0; i < 2000; i++ {
+		id := rnd.Intn(300)
+		switch rnd.Intn(4) {
+		case 0:
+			p := testPlayer(id, 0)
+			delete(players, p.ID)
+			lb.Remove(p.ID)
+		default:
+			// Coarse scores to exercise ties.
+			p := testPlayer(id, float64(rnd.Intn(50)))
+			players[p.ID] = p
+			lb.Update(p)
+		}
+	}
+
+	want := sorted(players)
+	if lb.Len() != len(want) {
+		t.Fatalf("Len() == %d, want %d", lb.Len(), len(want))
+	}
+	for i, p := range want {
+		rank, ok := lb.Rank(p.ID)
+		if !ok || rank != i+1 {
+			t.Errorf("Rank(%s) == %d, %t, want %d, true", p.ID, rank, ok, i+1)
+		}
+	}
+
+	page := lb.Page(11, 20)
+	if len(page) != 20 {
+		t.Fatalf("len(Page(11, 20)) == %d, want 20", len(page))
+	}
+	for i, e := range page {
+		if e.Player.ID != want[10+i].ID || e.Rank != 11+i || e.Score != want[10+i].Mu() {
+			t.Errorf("Page(11, 20)[%d] == %s (rank %d), want %s (rank %d)", i, e.Player.ID, e.Rank, want[10+i].ID, 11+i)
+		}
+	}
+}
+
+func TestLeaderboardQueries(t *testing.T) {
+	lb := NewWithScore(muScore)
+	for i := 0; i < 10; i++ {
+		lb.Update(testPlayer(i, float64(i)))
+	}
+
+	top := lb.Top(3)
+	for i, want := range []string{"p0009", "p0008", "p0007"} {
+		if top[i].Player.ID != want || top[i].Rank != i+1 {
+			t.Errorf("Top(3)[%d] == %s (rank %d), want %s (rank %d)", i, top[i].Player.ID, top[i].Rank, want, i+1)
+		}
+	}
+	if got := lb.Top(20); len(got) != 10 {
+		t.Errorf("len(Top(20)) == %d, want 10", len(got))
+	}
+
+	around, ok := lb.Around("p0008", 2, 2)
+	if !ok {
+		t.Fatal("Around(p0008) not found")
+	}
+	var ids []string
+	for _, e := range around {
+		ids = append(ids, e.Player.ID)
+	}
+	if got, want := fmt.Sprint(ids), "[p0009 p0008 p0007 p0006]"; got != want {
+		t.Errorf("Around(p0008, 2, 2) == %s, want %s", got, want)
+	}
+
+	for _, tt := range []struct {
+		id   string
+		want float64
+	}{
+		{"p0009", 100},
+		{"p0000", 0},
+		{"p0003", 100.0 / 3},
+	} {
+		if got, _ := lb.Percentile(tt.id); got != tt.want {
+			t.Errorf("Percentile(%s) == %v, want %v", tt.id, got, tt.want)
+		}
+		e, _ := lb.AtPercentile(tt.want)
+		if e.Player.ID != tt.id {
+			t.Errorf("AtPercentile(%v) == %s, want %s", tt.want, e.Player.ID, tt.id)
+		}
+	}
+
+	// Moving a player updates its position.
+	lb.Update(testPlayer(0, 100))
+	if rank, _ := lb.Rank("p0000"); rank != 1 {
+		t.Errorf("Rank(p0000) == %d after update, want 1", rank)
+	}
+	if lb.Len() != 10 {
+		t.Errorf("Len() == %d after update, want 10", lb.Len())
+	}
+	if _, ok := lb.Rank("missing"); ok {
+		t.Error("Rank(missing) found")
+	}
+}
+
+func TestLeaderboardTrueSkill(t *testing.T) {
+	ts := trueskill.New()
+	lb := New(ts)
+	p := trueskill.NewIdentifiedPlayer("a", trueskill.NewPlayer(30, 1))
+	lb.Update(p)
+
+	e, ok := lb.Get("a")
+	if !ok || e.Score != ts.TrueSkill(p.Player) {
+		t.Errorf("Get(a).Score == %v, want %v", e.Score, ts.TrueSkill(p.Player))
+	}
+}
+
+func BenchmarkLeaderboardUpdate(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	lb := NewWithScore(muScore)
+	for i := 0; i < 100000; i++ {
+		lb.Update(testPlayer(i, rnd.NormFloat64()*8+25))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lb.Update(testPlayer(i%100000, rnd.NormFloat64()*8+25))
+	}
+}
@@ -0,0 +1,123 @@
+package leaderboard
+
+import "github.com/mafredri/go-trueskill"
+
+// node is a node in a treap ordered by score (descending) and player ID
+// (ascending). Every node keeps the size of its subtree so that positions
+// can be computed in logarithmic time.
+type node struct {
+	player   trueskill.IdentifiedPlayer
+	score    float64
+	priority int64
+	size     int
+
+	left, right *node
+}
+
+func (n *node) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node) update() {
+	n.size = n.left.len() + n.right.len() + 1
+}
+
+// less reports whether n is ranked above o.
+func (n *node) less(o *node) bool {
+	if n.score != o.score {
+		return n.score > o.score
+	}
+	return n.player.ID < o.player.ID
+}
+
+func (n *node) entry(rank int) Entry {
+	return Entry{Player: n.player, Score: n.score, Rank: rank}
+}
+
+// at returns the node at the zero-based position i.
+func (n *node) at(i int) *node {
+	for n != nil {
+		l := n.left.len()
+		switch {
+		case i < l:
+			n = n.left
+		case i == l:
+			return n
+		default:
+			i -= l + 1
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// walk calls fn in order for every node with a zero-based position in
+// [from, to).
+func (n *node) walk(from, to int, fn func(n *node, i int)) {
+	n.walkOffset(0, from, to, fn)
+}
+
+func (n *node) walkOffset(offset, from, to int, fn func(n *node, i int)) {
+	if n == nil || offset >= to || offset+n.size <= from {
+		return
+	}
+	n.left.walkOffset(offset, from, to, fn)
+	i := offset + n.left.len()
+	if i >= from && i < to {
+		fn(n, i)
+	}
+	n.right.walkOffset(i+1, from, to, fn)
+}
+
+// split splits the treap t into the nodes ranked above key and the nodes
+// ranked equal to or below key.
+func split(t, key *node) (left, right *node) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.less(key) {
+		t.right, right = split(t.right, key)
+		t.update()
+		return t, right
+	}
+	left, t.left = split(t.left, key)
+	t.update()
+	return left, t
+}
+
+// splitAt splits the treap t into the first i nodes and the rest.
+func splitAt(t *node, i int) (left, right *node) {
+	if t == nil {
+		return nil, nil
+	}
+	if l := t.left.len(); i <= l {
+		left, t.left = splitAt(t.left, i)
+		t.update()
+		return left, t
+	}
+	t.right, right = splitAt(t.right, i-t.left.len()-1)
+	t.update()
+	return t, right
+}
+
+// merge joins the treaps left and right, all nodes in left must be ranked
+// above the nodes in right.
+func merge(left, right *node) *node {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.priority > right.priority:
+		left.right = merge(left.right, right)
+		left.update()
+		return left
+	default:
+		right.left = merge(left, right.left)
+		right.update()
+		return right
+	}
+}
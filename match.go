@@ -0,0 +1,187 @@
+package trueskill
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+)
+
+var (
+	errSideOutOfRange     = errors.New("advantage side out of range")
+	errHandicapOutOfRange = errors.New("handicap player out of range")
+	errHandicapNotFinite  = errors.New("handicap must be a finite number")
+	errAnchorOutOfRange   = errors.New("anchor player out of range")
+	errAllAnchored        = errors.New("at least one player must not be anchored")
+)
+
+// Advantage is the systematic advantage of one side of an asymmetric game,
+// e.g. home field, the white pieces or attacking. It is added to the
+// performance of the side that has it and is learned from the outcomes
+// alongside the skills of the players, so that the bias of the game is not
+// absorbed into the skills.
+type Advantage struct {
+	gaussian.Gaussian
+}
+
+// NewAdvantage returns an advantage from the provided mu (mean) and sigma
+// (standard deviation). A game that is not known to favor either side starts
+// at mu zero.
+func NewAdvantage(mu, sigma float64) Advantage {
+	return Advantage{
+		Gaussian: gaussian.NewFromMeanAndStdDev(mu, sigma),
+	}
+}
+
+// Mu returns the advantage mean.
+func (a Advantage) Mu() float64 {
+	return a.Mean()
+}
+
+// Sigma returns the advantage standard deviation.
+func (a Advantage) Sigma() float64 {
+	return a.StdDev()
+}
+
+func (a Advantage) String() string {
+	return fmt.Sprintf("Advantage(mu=%.3f sigma=%.3f)", a.Mu(), a.Sigma())
+}
+
+// MatchOption represents an option for a single match.
+type MatchOption func(m *matchConfig)
+
+// matchConfig is the configuration of a single match.
+type matchConfig struct {
+	advantage     Advantage
+	advantageSide int             // Index of the player with the advantage, -1 if none
+	handicaps     map[int]float64 // Handicap of every player index, if any
+	anchors       map[int]bool    // Player indexes with known skills
+}
+
+// SideAdvantage adds the advantage a to the performance of players[side].
+// The posterior of the advantage is returned in Result.Advantage. The
+// dynamics factor tau is not added to the advantage.
+func SideAdvantage(side int, a Advantage) MatchOption {
+	return func(m *matchConfig) {
+		m.advantage = a
+		m.advantageSide = side
+	}
+}
+
+// Handicap adds a known offset to the performance of players[player] in this
+// match, e.g. a handicap stone or a weapon restriction. A positive offset
+// improves the performance and a negative offset handicaps the player. The
+// offset is not learned, it shifts the performance difference before the
+// comparison so that a win against a handicap counts for more. Handicaps of
+// the same player are added together.
+func Handicap(player int, offset float64) MatchOption {
+	return func(m *matchConfig) {
+		if m.handicaps == nil {
+			m.handicaps = make(map[int]float64)
+		}
+		m.handicaps[player] += offset
+	}
+}
+
+// Anchor treats the skill of players[player] as known, e.g. a bot of fixed
+// difficulty or a reference player. The skill of an anchored player shapes
+// the updates of the other players but is returned unchanged, it neither
+// drifts nor absorbs rating. The dynamics factor tau is not added to the
+// skill of an anchored player, a small sigma makes the skill close to
+// exactly known.
+func Anchor(player int) MatchOption {
+	return func(m *matchConfig) {
+		if m.anchors == nil {
+			m.anchors = make(map[int]bool)
+		}
+		m.anchors[player] = true
+	}
+}
+
+// newMatchConfig applies the options to the configuration of a match between
+// n players. An error is returned if any of the options is invalid.
+func newMatchConfig(n int, opts []MatchOption) (matchConfig, error) {
+	m := matchConfig{advantageSide: -1}
+	for _, o := range opts {
+		o(&m)
+	}
+
+	if m.advantageSide != -1 {
+		if m.advantageSide < 0 || m.advantageSide >= n {
+			return matchConfig{}, errSideOutOfRange
+		}
+		if err := m.advantage.Check(); err != nil {
+			return matchConfig{}, fmt.Errorf("advantage: %v", err)
+		}
+	}
+	for i, h := range m.handicaps {
+		if i < 0 || i >= n {
+			return matchConfig{}, errHandicapOutOfRange
+		}
+		if !isFinite(h) {
+			return matchConfig{}, errHandicapNotFinite
+		}
+	}
+	for i := range m.anchors {
+		if i < 0 || i >= n {
+			return matchConfig{}, errAnchorOutOfRange
+		}
+	}
+	if len(m.anchors) == n {
+		return matchConfig{}, errAllAnchored
+	}
+	return m, nil
+}
+
+// matchPlayers returns the players of a match on a factor graph.
+func (m matchConfig) matchPlayers(players []Player) []matchPlayer {
+	mps := make([]matchPlayer, len(players))
+	for i, p := range players {
+		mps[i] = m.matchPlayer(i, p)
+	}
+	return mps
+}
+
+// matchPlayer returns player i of a match on a factor graph with the
+// provided skills.
+func (m matchConfig) matchPlayer(i int, skills ...Player) matchPlayer {
+	return matchPlayer{
+		skills:    skills,
+		advantage: i == m.advantageSide,
+		handicap:  m.handicaps[i],
+		anchored:  m.anchors[i],
+	}
+}
+
+// rateMatch rates a match like rate on a factor graph, mps are the players
+// built by matchConfig.matchPlayers in the same order as players.
+func (ts Config) rateMatch(players []Player, mps []matchPlayer, draws []bool, advantage Advantage) (Result, error) {
+	g := ts.buildMatchGraph(mps, draws, advantage)
+	g.run()
+
+	r := Result{
+		LoopIterations: g.loop.iterations,
+		LoopDelta:      g.loop.delta,
+	}
+	for i := range players {
+		p := players[i]
+		if !mps[i].anchored {
+			p = g.skill(i, 0)
+		}
+		r.Players = append(r.Players, p)
+		r.Changes = append(r.Changes, ts.skillChange(players[i], p))
+	}
+	for _, v := range g.perfs {
+		r.Performances = append(r.Performances, g.graph.Marginal(v))
+	}
+	for _, v := range g.diffs {
+		r.PerformanceDifferences = append(r.PerformanceDifferences, g.graph.Marginal(v))
+	}
+	if g.advantage != -1 {
+		r.Advantage = Advantage{Gaussian: g.graph.Marginal(g.advantage)}
+	}
+
+	r.LogEvidence = g.graph.LogNormalization()
+
+	return r, g.graph.Err()
+}
@@ -0,0 +1,132 @@
+package trueskill
+
+import (
+	"strconv"
+
+	"github.com/mafredri/go-trueskill/factor"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+// matchPlayer is a player of a match rated on a factor.Graph. The
+// performance of the player is based on the sum of its skills. The dynamics
+// factor tau is added to the first skill only, so that the sum drifts by tau
+// like a single skill does.
+type matchPlayer struct {
+	skills    []Player
+	advantage bool    // Advantage is added to the performance
+	handicap  float64 // Known offset added to the performance
+	anchored  bool    // Skills are known and not updated
+}
+
+// matchGraph is the factor graph of a match built on a factor.Graph, used
+// for the layouts not covered by the hand written schedule of
+// buildSkillFactorSchedule. Its schedule is derived from the factors.
+type matchGraph struct {
+	graph     *factor.Graph
+	skills    [][]int // Variables of the skills of every player, nil if anchored
+	perfs     []int   // Variable of the performance of every player
+	diffs     []int   // Variable of the performance difference of players i and i+1
+	advantage int     // Variable of the advantage, -1 if none
+	sched     schedule.Runner
+	loop      *loopStats
+}
+
+// buildMatchGraph builds the factor graph of a match between players ranked
+// in order, draws[i] reports whether players[i] and players[i+1] drew. The
+// advantage is only added to the graph if a player has it.
+func (ts Config) buildMatchGraph(players []matchPlayer, draws []bool, advantage Advantage) *matchGraph {
+	g := factor.NewGraph()
+	m := &matchGraph{graph: g, advantage: -1}
+
+	for _, p := range players {
+		if p.advantage {
+			m.advantage = g.NewVariable("advantage")
+			g.Prior("advantagePrior", m.advantage, advantage.Mean(), advantage.Variance())
+			break
+		}
+	}
+
+	for i, p := range players {
+		n := strconv.Itoa(i)
+
+		if p.anchored {
+			// The performance of an anchored player has a fixed prior,
+			// nothing is sent back to the skills.
+			var mean, variance float64
+			for _, s := range p.skills {
+				mean += s.Mean()
+				variance += s.Variance()
+			}
+			perf := g.NewVariable("perf" + n)
+			g.Prior("anchor"+n, perf, mean, variance+ts.beta*ts.beta)
+			m.skills = append(m.skills, nil)
+			m.perfs = append(m.perfs, perf)
+			continue
+		}
+
+		var skills []int
+		weights := make([]float64, len(p.skills))
+		for j, s := range p.skills {
+			variance := s.Variance()
+			if j == 0 {
+				variance += ts.tau * ts.tau
+			}
+			v := g.NewVariable("skill" + n + "." + strconv.Itoa(j))
+			g.Prior("prior"+n+"."+strconv.Itoa(j), v, s.Mean(), variance)
+			skills = append(skills, v)
+			weights[j] = 1
+		}
+		m.skills = append(m.skills, skills)
+
+		skill := skills[0]
+		if len(skills) > 1 {
+			skill = g.NewVariable("skill" + n)
+			g.WeightedSum("skillSum"+n, skill, weights, skills...)
+		}
+
+		perf := g.NewVariable("perf" + n)
+		g.Likelihood("likelihood"+n, perf, skill, ts.beta*ts.beta)
+		m.perfs = append(m.perfs, perf)
+	}
+
+	for i, draw := range draws {
+		n := strconv.Itoa(i)
+		diff := g.NewVariable("diff" + n)
+		weights := []float64{1, -1}
+		terms := []int{m.perfs[i], m.perfs[i+1]}
+		if players[i].advantage {
+			weights = append(weights, 1)
+			terms = append(terms, m.advantage)
+		}
+		if players[i+1].advantage {
+			weights = append(weights, -1)
+			terms = append(terms, m.advantage)
+		}
+		g.WeightedSum("sum"+n, diff, weights, terms...)
+		m.diffs = append(m.diffs, diff)
+
+		// The handicaps shift the performance difference before it is
+		// compared to the draw margin.
+		epsilon := drawMargin(ts.beta, ts.drawProbability, 2)
+		offset := players[i].handicap - players[i+1].handicap
+		if draw {
+			g.WithinOffset("within"+n, diff, epsilon, offset)
+		} else {
+			g.GreaterThan("greaterThan"+n, diff, epsilon-offset)
+		}
+	}
+
+	m.sched = g.Schedule(loopMaxDelta)
+	return m
+}
+
+// run runs the schedule of the match and records the progress of its loops.
+func (m *matchGraph) run() {
+	m.loop = new(loopStats)
+	_ = schedule.Run(m.sched, -1, schedule.Trace(m.loop))
+}
+
+// skill returns the posterior of skill j of player i.
+func (m *matchGraph) skill(i, j int) Player {
+	return Player{Gaussian: m.graph.Marginal(m.skills[i][j])}
+}
@@ -1,6 +1,10 @@
 package trueskill
 
-import "math"
+import (
+	"math"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
 
 func calculate2PlayerMatchQuality(ts Config, p1 Player, p2 Player) float64 {
 	betaSquared := ts.beta * ts.beta
@@ -13,3 +17,73 @@ func calculate2PlayerMatchQuality(ts Config, p1 Player, p2 Player) float64 {
 
 	return sqrt * exp
 }
+
+// calculateTeamMatchQuality returns the match quality for any number of
+// teams using the matrix form from the TrueSkill paper:
+//
+//	sqrt(det(β²AᵀA) / det(β²AᵀA + AᵀΣA)) * exp(-½ μᵀA (β²AᵀA + AᵀΣA)⁻¹ Aᵀμ)
+//
+// Where μ and Σ hold the means and variances of all players and column j of
+// A is the difference between the performance of team j and team j+1.
+func calculateTeamMatchQuality(ts Config, teams [][]Player) float64 {
+	betaSquared := ts.beta * ts.beta
+
+	var mean, variance []float64
+	var team []int
+	for i, t := range teams {
+		for _, p := range t {
+			mean = append(mean, p.Mu())
+			variance = append(variance, p.Variance())
+			team = append(team, i)
+		}
+	}
+
+	// a[i][j] is the weight of player i in performance difference j.
+	n := len(teams) - 1
+	a := make([][]float64, len(mean))
+	for i := range a {
+		a[i] = make([]float64, n)
+		if team[i] < n {
+			a[i][team[i]] = 1
+		}
+		if team[i] > 0 {
+			a[i][team[i]-1] = -1
+		}
+	}
+
+	ata := make([][]float64, n)
+	c := make([][]float64, n)
+	atMean := make([]float64, n)
+	for j := 0; j < n; j++ {
+		ata[j] = make([]float64, n)
+		c[j] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			var sum, weighted float64
+			for i := range a {
+				sum += a[i][j] * a[i][k]
+				weighted += a[i][j] * variance[i] * a[i][k]
+			}
+			ata[j][k] = betaSquared * sum
+			c[j][k] = ata[j][k] + weighted
+		}
+		for i := range a {
+			atMean[j] += a[i][j] * mean[i]
+		}
+	}
+
+	ataChol, err := mathextra.NewCholesky(ata)
+	if err != nil {
+		return 0
+	}
+	cChol, err := mathextra.NewCholesky(c)
+	if err != nil {
+		return 0
+	}
+
+	var exponent float64
+	for j, x := range cChol.Solve(atMean) {
+		exponent += atMean[j] * x
+	}
+
+	return math.Exp((ataChol.LogDet()-cChol.LogDet())/2 - exponent/2)
+}
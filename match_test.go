@@ -0,0 +1,224 @@
+package trueskill
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestRate_SideAdvantage(t *testing.T) {
+	ts := New(Tau(0))
+	players := []Player{NewPlayer(25, 6), NewPlayer(28, 4)}
+	adv := NewAdvantage(1, 3)
+
+	r, err := ts.Rate(players, []bool{false}, SideAdvantage(0, adv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The advantage is added to the performance of the first player, the
+	// same as rating a player with the combined skill.
+	combined := []Player{{Gaussian: players[0].Sum(adv.Gaussian)}, players[1]}
+	want, err := ts.Rate(combined, []bool{false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mathextra.Float64AlmostEq(r.LogEvidence, want.LogEvidence, 1e-6) {
+		t.Errorf("LogEvidence == %v, want %v", r.LogEvidence, want.LogEvidence)
+	}
+	if !mathextra.Float64AlmostEq(r.Players[1].Mu(), want.Players[1].Mu(), 1e-6) {
+		t.Errorf("Players[1].Mu() == %v, want %v", r.Players[1].Mu(), want.Players[1].Mu())
+	}
+
+	skillDelta := r.Players[0].Mu() - players[0].Mu()
+	advDelta := r.Advantage.Mu() - adv.Mu()
+	if !mathextra.Float64AlmostEq(skillDelta+advDelta, want.Changes[0].MuDelta, 1e-6) {
+		t.Errorf("mean change == %v, want %v", skillDelta+advDelta, want.Changes[0].MuDelta)
+	}
+	if ratio := players[0].Variance() / adv.Variance(); !mathextra.Float64AlmostEq(skillDelta/advDelta, ratio, 1e-6) {
+		t.Errorf("skill/advantage change == %v, want %v", skillDelta/advDelta, ratio)
+	}
+	if r.Advantage.Sigma() >= adv.Sigma() {
+		t.Errorf("Advantage.Sigma() == %v, want less than %v", r.Advantage.Sigma(), adv.Sigma())
+	}
+}
+
+func TestRate_SideAdvantageLearned(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer(), ts.NewPlayer()}
+	adv := NewAdvantage(0, 5)
+
+	// Players take turns to have the advantage and always win with it.
+	for game := 0; game < 30; game++ {
+		side := game % len(players)
+		order := []int{side, (side + 1) % len(players), (side + 2) % len(players)}
+		ranked := []Player{players[order[0]], players[order[1]], players[order[2]]}
+
+		r, err := ts.Rate(ranked, []bool{false, false}, SideAdvantage(0, adv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, i := range order {
+			players[i] = r.Players[k]
+		}
+		adv = r.Advantage
+	}
+
+	if adv.Mu() < 5 {
+		t.Errorf("Advantage.Mu() == %v, want at least 5", adv.Mu())
+	}
+	for i, p := range players {
+		if math.Abs(p.Mu()-ts.Mu()) > 2 {
+			t.Errorf("players[%d].Mu() == %v, want close to %v", i, p.Mu(), ts.Mu())
+		}
+	}
+}
+
+func TestRate_MatchOptionErrors(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer()}
+	if _, err := ts.Rate(players, []bool{false}, SideAdvantage(2, NewAdvantage(0, 1))); err == nil {
+		t.Error("Rate() with an out of range side did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, SideAdvantage(0, Advantage{})); err == nil {
+		t.Error("Rate() with an improper advantage did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, Handicap(-1, 1)); err == nil {
+		t.Error("Rate() with an out of range handicap did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, Handicap(0, math.Inf(1))); err == nil {
+		t.Error("Rate() with an infinite handicap did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, Anchor(2)); err == nil {
+		t.Error("Rate() with an out of range anchor did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, Anchor(0), Anchor(1)); err == nil {
+		t.Error("Rate() with every player anchored did not return an error")
+	}
+}
+
+func TestRate_Handicap(t *testing.T) {
+	ts := New()
+	players := []Player{NewPlayer(25, 6), NewPlayer(28, 4), NewPlayer(22, 5)}
+	handicaps := []float64{3, 0, -2}
+
+	// A handicap is a known shift of the performance, the same as shifting
+	// the skill of the player by the handicap for the match.
+	var shifted []Player
+	for i, p := range players {
+		shifted = append(shifted, NewPlayer(p.Mu()+handicaps[i], p.Sigma()))
+	}
+
+	for _, draws := range [][]bool{{false, false}, {true, false}, {false, true}} {
+		want, err := ts.Rate(shifted, draws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ts.Rate(players, draws, Handicap(0, handicaps[0]), Handicap(2, handicaps[2]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, p := range got.Players {
+			if !mathextra.Float64AlmostEq(p.Mu()+handicaps[i], want.Players[i].Mu(), 1e-4) ||
+				!mathextra.Float64AlmostEq(p.Sigma(), want.Players[i].Sigma(), 1e-4) {
+				t.Errorf("%v: Players[%d] == %v shifted by %v, want %v", draws, i, p, handicaps[i], want.Players[i])
+			}
+		}
+		if !mathextra.Float64AlmostEq(got.LogEvidence, want.LogEvidence, 1e-4) {
+			t.Errorf("%v: LogEvidence == %v, want %v", draws, got.LogEvidence, want.LogEvidence)
+		}
+	}
+}
+
+func TestPreviewOutcomes_Handicap(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer()}
+
+	previews, err := ts.PreviewOutcomes(players, Handicap(0, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ts.PreviewOutcomes([]Player{NewPlayer(ts.Mu()+5, ts.Sigma()), ts.NewPlayer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != len(want) {
+		t.Fatalf("got %d previews, want %d", len(previews), len(want))
+	}
+	for i, p := range previews {
+		if p.Outcome.String() != want[i].Outcome.String() ||
+			!mathextra.Float64AlmostEq(p.Probability, want[i].Probability, 1e-6) {
+			t.Errorf("previews[%d] == %v with probability %v, want %v with probability %v",
+				i, p.Outcome, p.Probability, want[i].Outcome, want[i].Probability)
+		}
+	}
+	if previews[0].Outcome.String() != "0 > 1" || previews[0].Probability <= 0.5 {
+		t.Errorf("previews[0] == %v with probability %v, want 0 > 1 with probability above 0.5", previews[0].Outcome, previews[0].Probability)
+	}
+}
+
+func TestRate_Anchor(t *testing.T) {
+	ts := New(Tau(0))
+	bot := NewPlayer(30, 1)
+	players := []Player{NewPlayer(25, 6), bot, NewPlayer(22, 5)}
+	draws := []bool{false, true}
+
+	// Without dynamics the other players are updated as if the anchor was
+	// rated, only the anchor itself is left unchanged.
+	want, err := ts.Rate(players, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ts.Rate(players, draws, Anchor(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Players[1] != bot {
+		t.Errorf("Players[1] == %v, want unchanged %v", got.Players[1], bot)
+	}
+	if got.Changes[1].MuDelta != 0 || got.Changes[1].SigmaDelta != 0 {
+		t.Errorf("Changes[1] == %+v, want no change", got.Changes[1])
+	}
+	for _, i := range []int{0, 2} {
+		if !mathextra.Float64AlmostEq(got.Players[i].Mu(), want.Players[i].Mu(), 1e-4) ||
+			!mathextra.Float64AlmostEq(got.Players[i].Sigma(), want.Players[i].Sigma(), 1e-4) {
+			t.Errorf("Players[%d] == %v, want %v", i, got.Players[i], want.Players[i])
+		}
+	}
+	if !mathextra.Float64AlmostEq(got.LogEvidence, want.LogEvidence, 1e-4) {
+		t.Errorf("LogEvidence == %v, want %v", got.LogEvidence, want.LogEvidence)
+	}
+}
+
+func TestRate_AnchorDoesNotDrift(t *testing.T) {
+	ts := New()
+	bot := NewPlayer(25, 0.5)
+	p := ts.NewPlayer()
+
+	for game := 0; game < 20; game++ {
+		r, err := ts.Rate([]Player{p, bot}, []bool{false}, Anchor(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Players[1] != bot {
+			t.Fatalf("game %d: bot == %v, want unchanged %v", game, r.Players[1], bot)
+		}
+		p = r.Players[0]
+	}
+	if p.Mu() <= bot.Mu() {
+		t.Errorf("player.Mu() == %v, want above %v", p.Mu(), bot.Mu())
+	}
+
+	previews, err := ts.PreviewOutcomes([]Player{p, bot}, Anchor(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, preview := range previews {
+		if preview.Changes[1].After != bot {
+			t.Errorf("%v: bot == %v, want unchanged %v", preview.Outcome, preview.Changes[1].After, bot)
+		}
+	}
+}
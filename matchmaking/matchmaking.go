@@ -0,0 +1,344 @@
+// Package matchmaking forms lobbies from a queue of waiting players by
+// maximising the TrueSkill match quality of each lobby.
+//
+// A lobby is only formed when its quality reaches a threshold. The threshold
+// is lowered the longer the oldest player in the lobby has waited, so that
+// players at the extremes of the skill distribution are eventually matched
+// too.
+package matchmaking
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/mafredri/go-trueskill"
+	"github.com/mafredri/go-trueskill/balance"
+)
+
+// Default matchmaking configuration.
+const (
+	DefaultSeed     = 1
+	DefaultPoolSize = 4  // Candidate pool size, in multiples of lobby size
+	DefaultSamples  = 64 // Random lobbies evaluated when the pool is too large
+)
+
+// Shape describes the teams of a lobby.
+type Shape struct {
+	Teams    int // Number of teams
+	TeamSize int // Number of players per team
+}
+
+// OneVsOne returns the shape of a head-to-head match.
+func OneVsOne() Shape {
+	return Shape{Teams: 2, TeamSize: 1}
+}
+
+// TeamVsTeam returns the shape of a match between two teams of n players.
+func TeamVsTeam(n int) Shape {
+	return Shape{Teams: 2, TeamSize: n}
+}
+
+// FreeForAll returns the shape of a free-for-all match between n players.
+func FreeForAll(n int) Shape {
+	return Shape{Teams: n, TeamSize: 1}
+}
+
+// Size returns the number of players in a lobby of this shape.
+func (s Shape) Size() int {
+	return s.Teams * s.TeamSize
+}
+
+// Threshold returns the minimum match quality a lobby must reach when the
+// longest waiting player in it has waited for the provided duration.
+type Threshold func(wait time.Duration) float64
+
+// LinearThreshold returns a Threshold that starts at start and is lowered by
+// step for every interval waited, but never below floor.
+func LinearThreshold(start, floor, step float64, interval time.Duration) Threshold {
+	return func(wait time.Duration) float64 {
+		steps := math.Floor(float64(wait) / float64(interval))
+		return math.Max(floor, start-steps*step)
+	}
+}
+
+// Ticket is a player waiting in the queue.
+type Ticket struct {
+	Player   trueskill.IdentifiedPlayer
+	Enqueued time.Time
+}
+
+// Lobby is a group of tickets split into teams.
+type Lobby struct {
+	Teams   [][]Ticket
+	Quality float64 // Match quality of the teams
+}
+
+// Players returns the players of each team.
+func (l Lobby) Players() [][]trueskill.Player {
+	teams := make([][]trueskill.Player, len(l.Teams))
+	for i, t := range l.Teams {
+		for _, tk := range t {
+			teams[i] = append(teams[i], tk.Player.Player)
+		}
+	}
+	return teams
+}
+
+// Option represents a matchmaker option.
+type Option func(m *Matchmaker)
+
+// Seed sets the seed used when sampling candidate lobbies.
+func Seed(seed int64) Option {
+	return func(m *Matchmaker) {
+		m.seed = seed
+	}
+}
+
+// QualityThreshold sets the minimum quality of a lobby.
+func QualityThreshold(threshold Threshold) Option {
+	return func(m *Matchmaker) {
+		m.threshold = threshold
+	}
+}
+
+// PoolSize sets the size of the candidate pool searched for every lobby as a
+// multiple of the lobby size. The candidates are the players closest in
+// skill to the longest waiting player.
+func PoolSize(multiple int) Option {
+	return func(m *Matchmaker) {
+		m.poolSize = multiple
+	}
+}
+
+// Samples sets the number of randomly sampled lobbies that are evaluated
+// when the candidate pool is too large to be searched exhaustively.
+func Samples(n int) Option {
+	return func(m *Matchmaker) {
+		m.samples = n
+	}
+}
+
+// Matchmaker keeps a queue of tickets and forms lobbies of a fixed shape.
+// Given the same seed, options and sequence of calls a Matchmaker always
+// forms the same lobbies.
+type Matchmaker struct {
+	ts        trueskill.Config
+	shape     Shape
+	threshold Threshold
+	seed      int64
+	poolSize  int
+	samples   int
+
+	rnd   *rand.Rand
+	queue []Ticket
+}
+
+// New returns a matchmaker for lobbies of the provided shape. By default a
+// lobby requires a quality of 0.5, lowered by 0.05 every ten seconds down to
+// zero.
+func New(ts trueskill.Config, shape Shape, opts ...Option) *Matchmaker {
+	m := &Matchmaker{
+		ts:        ts,
+		shape:     shape,
+		threshold: LinearThreshold(0.5, 0, 0.05, 10*time.Second),
+		seed:      DefaultSeed,
+		poolSize:  DefaultPoolSize,
+		samples:   DefaultSamples,
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	m.rnd = rand.New(rand.NewSource(m.seed))
+
+	return m
+}
+
+// Enqueue adds a ticket to the queue.
+func (m *Matchmaker) Enqueue(t Ticket) {
+	m.queue = append(m.queue, t)
+}
+
+// Remove removes the ticket of the player with the provided ID from the
+// queue and reports whether it was queued.
+func (m *Matchmaker) Remove(id string) bool {
+	for i, t := range m.queue {
+		if t.Player.ID == id {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of queued tickets.
+func (m *Matchmaker) Len() int {
+	return len(m.queue)
+}
+
+// Match forms as many lobbies as possible from the queue at time now and
+// removes their tickets from the queue. The longest waiting tickets are
+// matched first.
+func (m *Matchmaker) Match(now time.Time) []Lobby {
+	size := m.shape.Size()
+	if size < 2 || m.shape.Teams < 2 {
+		return nil
+	}
+
+	sort.SliceStable(m.queue, func(i, j int) bool {
+		if !m.queue[i].Enqueued.Equal(m.queue[j].Enqueued) {
+			return m.queue[i].Enqueued.Before(m.queue[j].Enqueued)
+		}
+		return m.queue[i].Player.ID < m.queue[j].Player.ID
+	})
+
+	var lobbies []Lobby
+	matched := make([]bool, len(m.queue))
+	for i := range m.queue {
+		if matched[i] {
+			continue
+		}
+
+		var candidates []int
+		for j := range m.queue {
+			if j != i && !matched[j] {
+				candidates = append(candidates, j)
+			}
+		}
+		if len(candidates) < size-1 {
+			break
+		}
+
+		lobby, members, ok := m.bestLobby(i, candidates)
+		if !ok || lobby.Quality < m.threshold(now.Sub(m.oldest(members))) {
+			continue
+		}
+
+		for _, j := range members {
+			matched[j] = true
+		}
+		lobbies = append(lobbies, lobby)
+	}
+
+	var queue []Ticket
+	for i, t := range m.queue {
+		if !matched[i] {
+			queue = append(queue, t)
+		}
+	}
+	m.queue = queue
+
+	return lobbies
+}
+
+// oldest returns the time the longest waiting of the members was enqueued.
+func (m *Matchmaker) oldest(members []int) time.Time {
+	oldest := m.queue[members[0]].Enqueued
+	for _, i := range members[1:] {
+		if t := m.queue[i].Enqueued; t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// bestLobby searches for the best lobby containing the anchor ticket among
+// the candidates. It returns the lobby and the queue indexes of its members.
+func (m *Matchmaker) bestLobby(anchor int, candidates []int) (Lobby, []int, bool) {
+	size := m.shape.Size()
+
+	// Only the players closest in skill to the anchor are considered.
+	mu := m.queue[anchor].Player.Mu()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return math.Abs(m.queue[candidates[i]].Player.Mu()-mu) < math.Abs(m.queue[candidates[j]].Player.Mu()-mu)
+	})
+	if pool := m.poolSize * size; pool > 0 && len(candidates) > pool {
+		candidates = candidates[:pool]
+	}
+
+	var best Lobby
+	var bestMembers []int
+	found := false
+	try := func(members []int) {
+		lobby, err := m.split(members)
+		if err != nil {
+			return
+		}
+		if !found || lobby.Quality > best.Quality {
+			best, found = lobby, true
+			bestMembers = append(bestMembers[:0], members...)
+		}
+	}
+
+	if binomial(len(candidates), size-1) <= float64(m.samples) {
+		combinations(len(candidates), size-1, func(c []int) {
+			members := []int{anchor}
+			for _, k := range c {
+				members = append(members, candidates[k])
+			}
+			try(members)
+		})
+	} else {
+		// The closest candidates are always evaluated, followed by
+		// random samples from the pool.
+		members := append([]int{anchor}, candidates[:size-1]...)
+		try(members)
+		for s := 0; s < m.samples; s++ {
+			members = []int{anchor}
+			for _, k := range m.rnd.Perm(len(candidates))[:size-1] {
+				members = append(members, candidates[k])
+			}
+			try(members)
+		}
+	}
+
+	return best, bestMembers, found
+}
+
+// split assigns the members to teams so that the match quality is
+// maximised. An error is returned if the members cannot be split into teams.
+func (m *Matchmaker) split(members []int) (Lobby, error) {
+	players := make([]trueskill.Player, len(members))
+	for k, i := range members {
+		players[k] = m.queue[i].Player.Player
+	}
+
+	r, err := balance.Teams(m.ts, players, m.shape.Teams, balance.Seed(m.seed))
+	if err != nil {
+		return Lobby{}, err
+	}
+
+	lobby := Lobby{Teams: make([][]Ticket, len(r.Teams)), Quality: r.Quality}
+	for t, team := range r.Teams {
+		for _, k := range team {
+			lobby.Teams[t] = append(lobby.Teams[t], m.queue[members[k]])
+		}
+	}
+	return lobby, nil
+}
+
+// combinations calls fn with every k-combination of the integers [0, n).
+func combinations(n, k int, fn func(c []int)) {
+	c := make([]int, k)
+	var gen func(i, start int)
+	gen = func(i, start int) {
+		if i == k {
+			fn(c)
+			return
+		}
+		for j := start; j <= n-(k-i); j++ {
+			c[i] = j
+			gen(i+1, j+1)
+		}
+	}
+	gen(0, 0)
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
@@ -0,0 +1,141 @@
+package matchmaking
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+var epoch = time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// simulate runs a simulated queue where players arrive every second with
+// random skills and the matchmaker is run every five seconds.
+func simulate(seed int64, shape Shape, opts ...Option) (lobbies []Lobby, matchedAt []time.Time, m *Matchmaker) {
+	ts := trueskill.New()
+	m = New(ts, shape, append([]Option{Seed(seed)}, opts...)...)
+	rnd := rand.New(rand.NewSource(seed))
+
+	now := epoch
+	for i := 0; i < 120; i++ {
+		p := trueskill.NewPlayer(25+rnd.NormFloat64()*8, 1+rnd.Float64()*7)
+		m.Enqueue(Ticket{
+			Player:   trueskill.NewIdentifiedPlayer(fmt.Sprintf("p%03d", i), p),
+			Enqueued: now,
+		})
+		now = now.Add(time.Second)
+		if i%5 == 4 {
+			for _, l := range m.Match(now) {
+				lobbies = append(lobbies, l)
+				matchedAt = append(matchedAt, now)
+			}
+		}
+	}
+	return lobbies, matchedAt, m
+}
+
+func TestMatchmakerSimulatedQueue(t *testing.T) {
+	threshold := LinearThreshold(0.5, 0.1, 0.1, 10*time.Second)
+
+	for _, shape := range []Shape{OneVsOne(), TeamVsTeam(2), FreeForAll(3)} {
+		lobbies, matchedAt, m := simulate(7, shape, QualityThreshold(threshold))
+		if len(lobbies) == 0 {
+			t.Fatalf("%+v: no lobbies formed", shape)
+		}
+
+		seen := make(map[string]bool)
+		for i, l := range lobbies {
+			if len(l.Teams) != shape.Teams {
+				t.Errorf("%+v: lobby %d has %d teams, want %d", shape, i, len(l.Teams), shape.Teams)
+			}
+			oldest := matchedAt[i]
+			for _, team := range l.Teams {
+				if len(team) != shape.TeamSize {
+					t.Errorf("%+v: lobby %d has team of %d, want %d", shape, i, len(team), shape.TeamSize)
+				}
+				for _, tk := range team {
+					if seen[tk.Player.ID] {
+						t.Errorf("%+v: %s matched twice", shape, tk.Player.ID)
+					}
+					seen[tk.Player.ID] = true
+					if tk.Enqueued.Before(oldest) {
+						oldest = tk.Enqueued
+					}
+				}
+			}
+
+			if min := threshold(matchedAt[i].Sub(oldest)); l.Quality < min {
+				t.Errorf("%+v: lobby %d quality %.3f below threshold %.3f", shape, i, l.Quality, min)
+			}
+			ts := trueskill.New()
+			if q := ts.TeamMatchQuality(l.Players()); q != l.Quality {
+				t.Errorf("%+v: lobby %d quality %.3f, want %.3f", shape, i, l.Quality, q)
+			}
+		}
+		if len(seen)+m.Len() != 120 {
+			t.Errorf("%+v: %d matched + %d queued, want 120", shape, len(seen), m.Len())
+		}
+	}
+}
+
+func TestMatchmakerDeterministic(t *testing.T) {
+	shape := TeamVsTeam(3)
+	a, _, _ := simulate(3, shape, Samples(8))
+	b, _, _ := simulate(3, shape, Samples(8))
+	if !reflect.DeepEqual(a, b) {
+		t.Error("lobbies differ between runs with the same seed")
+	}
+}
+
+func TestMatchmakerWaitRelaxesThreshold(t *testing.T) {
+	ts := trueskill.New()
+	m := New(ts, OneVsOne(), QualityThreshold(LinearThreshold(0.9, 0, 0.1, time.Minute)))
+	m.Enqueue(Ticket{trueskill.NewIdentifiedPlayer("a", trueskill.NewPlayer(20, 2)), epoch})
+	m.Enqueue(Ticket{trueskill.NewIdentifiedPlayer("b", trueskill.NewPlayer(30, 2)), epoch})
+
+	if l := m.Match(epoch); len(l) != 0 {
+		t.Fatalf("Match() formed %d lobbies, want 0", len(l))
+	}
+
+	lobbies := m.Match(epoch.Add(10 * time.Minute))
+	if len(lobbies) != 1 {
+		t.Fatalf("Match() formed %d lobbies, want 1", len(lobbies))
+	}
+	if m.Len() != 0 {
+		t.Errorf("Len() == %d, want 0", m.Len())
+	}
+}
+
+func TestMatchmakerOldestMemberRelaxesThreshold(t *testing.T) {
+	ts := trueskill.New()
+	m := New(ts, OneVsOne(), QualityThreshold(LinearThreshold(0.9, 0, 0.1, time.Minute)))
+	now := epoch.Add(10 * time.Minute)
+	m.Enqueue(Ticket{trueskill.NewIdentifiedPlayer("a", trueskill.NewPlayer(20, 2)), now})
+	m.Enqueue(Ticket{trueskill.NewIdentifiedPlayer("b", trueskill.NewPlayer(30, 2)), epoch})
+
+	// The lobby is anchored on a, which has not waited, but b has.
+	if l := m.Match(now); len(l) != 1 {
+		t.Fatalf("Match() formed %d lobbies, want 1", len(l))
+	}
+}
+
+func TestMatchmakerSplitsTeamsFairly(t *testing.T) {
+	ts := trueskill.New()
+	m := New(ts, TeamVsTeam(2), QualityThreshold(LinearThreshold(0, 0, 0, time.Second)))
+	for i, mu := range []float64{10, 20, 30, 40} {
+		m.Enqueue(Ticket{trueskill.NewIdentifiedPlayer(fmt.Sprint(i), trueskill.NewPlayer(mu, 1)), epoch})
+	}
+
+	lobbies := m.Match(epoch)
+	if len(lobbies) != 1 {
+		t.Fatalf("Match() formed %d lobbies, want 1", len(lobbies))
+	}
+	for _, team := range lobbies[0].Teams {
+		if sum := team[0].Player.Mu() + team[1].Player.Mu(); sum != 50 {
+			t.Errorf("team skill sum == %v, want 50", sum)
+		}
+	}
+}
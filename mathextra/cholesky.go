@@ -0,0 +1,114 @@
+package mathextra
+
+import (
+	"errors"
+	"math"
+)
+
+var errNotPositiveDefinite = errors.New("matrix is not symmetric positive definite")
+
+// Cholesky is the Cholesky decomposition (A = L*Lᵀ) of a symmetric positive
+// definite matrix.
+type Cholesky struct {
+	l [][]float64 // Lower triangular factor
+}
+
+// NewCholesky decomposes the symmetric positive definite matrix a, given in
+// row-major order. Only the lower triangle of a is used. An error is returned
+// if a is not positive definite.
+func NewCholesky(a [][]float64) (Cholesky, error) {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		if len(a[i]) != n {
+			return Cholesky{}, errNotPositiveDefinite
+		}
+		l[i] = make([]float64, n)
+	}
+
+	for j := 0; j < n; j++ {
+		d := a[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if !(d > 0) || math.IsInf(d, 0) {
+			return Cholesky{}, errNotPositiveDefinite
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+
+	return Cholesky{l: l}, nil
+}
+
+// Size returns the number of rows (and columns) of the decomposed matrix.
+func (c Cholesky) Size() int {
+	return len(c.l)
+}
+
+// L returns a copy of the lower triangular factor.
+func (c Cholesky) L() [][]float64 {
+	l := make([][]float64, len(c.l))
+	for i := range l {
+		l[i] = append([]float64(nil), c.l[i]...)
+	}
+	return l
+}
+
+// LogDet returns the natural logarithm of the determinant of the decomposed
+// matrix.
+func (c Cholesky) LogDet() float64 {
+	var logDet float64
+	for i := range c.l {
+		logDet += 2 * math.Log(c.l[i][i])
+	}
+	return logDet
+}
+
+// Solve returns x such that A*x = b.
+func (c Cholesky) Solve(b []float64) []float64 {
+	n := len(c.l)
+	x := append([]float64(nil), b...)
+
+	// Forward substitution, L*y = b.
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= c.l[i][k] * x[k]
+		}
+		x[i] /= c.l[i][i]
+	}
+	// Back substitution, Lᵀ*x = y.
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			x[i] -= c.l[k][i] * x[k]
+		}
+		x[i] /= c.l[i][i]
+	}
+
+	return x
+}
+
+// Inverse returns the inverse of the decomposed matrix.
+func (c Cholesky) Inverse() [][]float64 {
+	n := len(c.l)
+	inv := make([][]float64, n)
+	for i := range inv {
+		inv[i] = make([]float64, n)
+	}
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := c.Solve(e)
+		e[j] = 0
+		for i := 0; i < n; i++ {
+			inv[i][j] = col[i]
+		}
+	}
+	return inv
+}
@@ -0,0 +1,79 @@
+package mathextra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCholesky(t *testing.T) {
+	a := [][]float64{
+		{4, 12, -16},
+		{12, 37, -43},
+		{-16, -43, 98},
+	}
+	wantL := [][]float64{
+		{2, 0, 0},
+		{6, 1, 0},
+		{-8, 5, 3},
+	}
+
+	c, err := NewCholesky(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := c.L()
+	for i := range wantL {
+		for j := range wantL[i] {
+			if !Float64AlmostEq(l[i][j], wantL[i][j], 1e-12) {
+				t.Errorf("L()[%d][%d] == %v, want %v", i, j, l[i][j], wantL[i][j])
+			}
+		}
+	}
+
+	wantLogDet := math.Log(36)
+	if !Float64AlmostEq(c.LogDet(), wantLogDet, 1e-12) {
+		t.Errorf("LogDet() == %v, want %v", c.LogDet(), wantLogDet)
+	}
+
+	b := []float64{1, 2, 3}
+	x := c.Solve(b)
+	for i := range a {
+		var got float64
+		for j := range a[i] {
+			got += a[i][j] * x[j]
+		}
+		if !Float64AlmostEq(got, b[i], 1e-9) {
+			t.Errorf("(A*Solve(b))[%d] == %v, want %v", i, got, b[i])
+		}
+	}
+
+	inv := c.Inverse()
+	for i := range a {
+		for j := range a {
+			var got, want float64
+			for k := range a {
+				got += a[i][k] * inv[k][j]
+			}
+			if i == j {
+				want = 1
+			}
+			if !Float64AlmostEq(got, want, 1e-9) {
+				t.Errorf("(A*Inverse())[%d][%d] == %v, want %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestCholeskyNotPositiveDefinite(t *testing.T) {
+	for _, a := range [][][]float64{
+		{{1, 2}, {2, 1}},
+		{{0}},
+		{{1, 0}, {0}},
+		{{math.NaN()}},
+	} {
+		if _, err := NewCholesky(a); err == nil {
+			t.Errorf("NewCholesky(%v) did not return an error", a)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package mathextra
+
+import "math"
+
+// MillsRatioDirect is the point from which MillsRatio is evaluated as a
+// continued fraction instead of a ratio of the tail probability and the
+// density.
+const MillsRatioDirect = 5
+
+// millsRatioTerms is the number of terms of the continued fraction, enough
+// for full precision from MillsRatioDirect and up.
+const millsRatioTerms = 80
+
+// MillsRatio returns the Mills ratio of the standard normal distribution,
+// the upper tail probability at x divided by the density at x.
+func MillsRatio(x float64) float64 {
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+	if x < MillsRatioDirect {
+		return Erfc(x/math.Sqrt2) / 2 / (InvSqrt2Pi * math.Exp(-x*x/2))
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	return 1 / millsRatioFraction(x, 1)
+}
+
+// InvMillsRatioExcess returns 1/MillsRatio(x) - x, the amount by which the
+// inverse Mills ratio exceeds x. It is evaluated without the cancellation of
+// the subtraction for large x.
+func InvMillsRatioExcess(x float64) float64 {
+	if math.IsNaN(x) {
+		return math.NaN()
+	}
+	if x < MillsRatioDirect {
+		return 1/MillsRatio(x) - x
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	// 1/MillsRatio(x) is x+1/(x+2/(x+3/(x+...))).
+	return 1 / millsRatioFraction(x, 2)
+}
+
+// millsRatioFraction returns the tail x+k/(x+(k+1)/(x+...)) of Laplace's
+// continued fraction of the Mills ratio 1/(x+1/(x+2/(x+3/(x+...)))),
+// evaluated from the end.
+func millsRatioFraction(x float64, k int) float64 {
+	f := x
+	for i := millsRatioTerms; i >= k; i-- {
+		f = x + float64(i)/f
+	}
+	return f
+}
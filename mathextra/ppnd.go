@@ -0,0 +1,157 @@
+package mathextra
+
+import "math"
+
+// NormalQuantile returns the quantile (inverse cumulative distribution
+// function) of the standard normal distribution at p using Wichura's
+// algorithm AS241 (PPND16). The relative error is about 1e-16 over the whole
+// range of p, away from p = 0.5 where the quantile is zero. Below 1e-300,
+// outside the range of AS241, the quantile is refined with Newton's method.
+// NormalQuantile returns -Inf for p = 0, +Inf for p = 1 and NaN outside
+// [0, 1].
+//
+// Wichura, M. J. (1988). Algorithm AS241: The percentage points of the
+// normal distribution. Applied Statistics, 37, 477-484.
+func NormalQuantile(p float64) float64 {
+	switch {
+	case p < 0 || p > 1 || math.IsNaN(p):
+		return math.NaN()
+	case p == 0:
+		return math.Inf(-1)
+	case p == 1:
+		return math.Inf(1)
+	}
+
+	q := p - 0.5
+	if math.Abs(q) <= ppndSplit1 {
+		return ppndCentral(q)
+	}
+	return math.Copysign(ppndTailLog(logTail(math.Min(p, 1-p))), q)
+}
+
+// minNormalFloat64 is the smallest positive normal float64.
+const minNormalFloat64 = 2.2250738585072014e-308
+
+// logTail returns the natural logarithm of the tail probability r, scaling
+// subnormal values for which math.Log is inaccurate.
+func logTail(r float64) float64 {
+	if r < minNormalFloat64 {
+		return math.Log(r*(1<<52)) - 52*math.Ln2
+	}
+	return math.Log(r)
+}
+
+// NormalQuantileLog returns NormalQuantile(exp(logP)) without the loss of
+// precision of exp(logP) underflowing or rounding to one. It is the inverse
+// of the logarithm of the cumulative distribution function.
+func NormalQuantileLog(logP float64) float64 {
+	switch {
+	case logP > 0 || math.IsNaN(logP):
+		return math.NaN()
+	case logP == 0:
+		return math.Inf(1)
+	case math.IsInf(logP, -1):
+		return math.Inf(-1)
+	}
+
+	p := math.Exp(logP)
+	switch q := p - 0.5; {
+	case math.Abs(q) <= ppndSplit1:
+		return ppndCentral(q)
+	case q < 0:
+		return -ppndTailLog(logP)
+	default:
+		return ppndTailLog(math.Log(-math.Expm1(logP)))
+	}
+}
+
+// InvErf returns the inverse error function of x.
+func InvErf(x float64) float64 {
+	switch {
+	case x < -1 || x > 1 || math.IsNaN(x):
+		return math.NaN()
+	case x == -1:
+		return math.Inf(-1)
+	case x == 1:
+		return math.Inf(1)
+	}
+
+	// InvErf(x) = NormalQuantile((1+x)/2)/sqrt(2), q = x/2 is exact.
+	q := x / 2
+	if math.Abs(q) <= ppndSplit1 {
+		return ppndCentral(q) / math.Sqrt2
+	}
+	return math.Copysign(ppndTailLog(logTail((1-math.Abs(x))/2)), q) / math.Sqrt2
+}
+
+const (
+	ppndSplit1 = 0.425
+	ppndSplit2 = 5.0
+	ppndConst1 = 0.180625
+	ppndConst2 = 1.6
+
+	// ppndTailMax is the largest sqrt(-log(r)) in the range of AS241,
+	// r = 1e-300.
+	ppndTailMax = 26.3
+)
+
+// ppndTailLog returns the absolute value of the quantile at the tail
+// probability exp(logR).
+func ppndTailLog(logR float64) float64 {
+	s := math.Sqrt(-logR)
+	x := ppndTail(s)
+	if s <= ppndTailMax {
+		return x
+	}
+
+	// Solve log(1 - Phi(x)) = logR with Newton's method, the derivative
+	// of the left-hand side is -1/MillsRatio(x).
+	for i := 0; i < 20; i++ {
+		m := MillsRatio(x)
+		dx := (-x*x/2 - LogSqrt2Pi + math.Log(m) - logR) * m
+		x += dx
+		if math.Abs(dx) <= 1e-16*x {
+			break
+		}
+	}
+	return x
+}
+
+// ppndCentral returns the quantile at p = q + 0.5 for |q| <= ppndSplit1.
+func ppndCentral(q float64) float64 {
+	r := ppndConst1 - q*q
+	return q * (((((((2.5090809287301226727e+3*r+3.3430575583588128105e+4)*r+
+		6.7265770927008700853e+4)*r+4.5921953931549871457e+4)*r+
+		1.3731693765509461125e+4)*r+1.9715909503065514427e+3)*r+
+		1.3314166789178437745e+2)*r + 3.3871328727963666080e+0) /
+		(((((((5.2264952788528545610e+3*r+2.8729085735721942674e+4)*r+
+			3.9307895800092710610e+4)*r+2.1213794301586595867e+4)*r+
+			5.3941960214247511077e+3)*r+6.8718700749205790830e+2)*r+
+			4.2313330701600911252e+1)*r + 1.0)
+}
+
+// ppndTail returns the absolute value of the quantile at the tail
+// probability r = min(p, 1-p), given s = sqrt(-log(r)).
+func ppndTail(s float64) float64 {
+	if s <= ppndSplit2 {
+		r := s - ppndConst2
+		return (((((((7.74545014278341407640e-4*r+2.27238449892691845833e-2)*r+
+			2.41780725177450611770e-1)*r+1.27045825245236838258e+0)*r+
+			3.64784832476320460504e+0)*r+5.76949722146069140550e+0)*r+
+			4.63033784615654529590e+0)*r + 1.42343711074968357734e+0) /
+			(((((((1.05075007164441684324e-9*r+5.47593808499534494600e-4)*r+
+				1.51986665636164571966e-2)*r+1.48103976427480074590e-1)*r+
+				6.89767334985100004550e-1)*r+1.67638483018380384940e+0)*r+
+				2.05319162663775882187e+0)*r + 1.0)
+	}
+
+	r := s - ppndSplit2
+	return (((((((2.01033439929228813265e-7*r+2.71155556874348757815e-5)*r+
+		1.24266094738807843860e-3)*r+2.65321895265761230930e-2)*r+
+		2.96560571828504891230e-1)*r+1.78482653991729133580e+0)*r+
+		5.46378491116411436990e+0)*r + 6.65790464350110377720e+0) /
+		(((((((2.04426310338993978564e-15*r+1.42151175831644588870e-7)*r+
+			1.84631831751005468180e-5)*r+7.86869131145613259100e-4)*r+
+			1.48753612908506148525e-2)*r+1.36929880922735805310e-1)*r+
+			5.99832206555887937690e-1)*r + 1.0)
+}
@@ -0,0 +1,161 @@
+package mathextra
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values computed with 80 digit arithmetic for the exact float64
+// value of the input.
+var normalQuantileTests = []struct {
+	p, want float64
+}{
+	{1e-300, -37.047096299361201},
+	{1e-100, -21.273453560965326},
+	{1e-20, -9.262340089798407},
+	{1e-10, -6.3613409024040566},
+	{1e-5, -4.2648907939228247},
+	{0.001, -3.0902323061678136},
+	{0.01, -2.3263478740408412},
+	{0.02425, -1.9729610513118849},
+	{0.075, -1.4395314709384559},
+	{0.1, -1.2815515655446004},
+	{0.3, -0.52440051270804078},
+	{0.45, -0.12566134685507402},
+	{0.5, 0},
+	{0.55, 0.12566134685507416},
+	{0.9, 1.2815515655446006},
+	{0.925, 1.4395314709384561},
+	{0.975, 1.9599639845400538},
+	{0.999, 3.0902323061678132},
+	{0.9999999999, 6.3613408896974217},
+}
+
+// ppndAccuracy is the relative accuracy tested for, AS241 is accurate to
+// about 1e-16 but evaluating the rational functions costs a few ulps.
+const ppndAccuracy = 1e-15
+
+func relativeEq(got, want float64) bool {
+	if want == 0 {
+		return got == 0
+	}
+	return math.Abs(got-want) <= ppndAccuracy*math.Abs(want)
+}
+
+func TestNormalQuantile(t *testing.T) {
+	for _, tt := range normalQuantileTests {
+		if got := NormalQuantile(tt.p); !relativeEq(got, tt.want) {
+			t.Errorf("NormalQuantile(%g) == %.17g, want %.17g", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestNormalQuantile_Edges(t *testing.T) {
+	tests := []struct {
+		p, want float64
+	}{
+		{0, math.Inf(-1)},
+		{1, math.Inf(1)},
+		{math.SmallestNonzeroFloat64, -38.467405617144344},
+	}
+	for _, tt := range tests {
+		if got := NormalQuantile(tt.p); got != tt.want && !relativeEq(got, tt.want) {
+			t.Errorf("NormalQuantile(%g) == %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	for _, p := range []float64{-0.1, 1.1, math.NaN(), math.Inf(1)} {
+		if got := NormalQuantile(p); !math.IsNaN(got) {
+			t.Errorf("NormalQuantile(%g) == %v, want NaN", p, got)
+		}
+	}
+}
+
+func TestNormalQuantile_Symmetry(t *testing.T) {
+	for p := 1e-300; p < 0.5; p *= 3 {
+		lower, upper := NormalQuantile(p), -NormalQuantile(p)
+		if got := InvErfc(2 * p); !relativeEq(got*-math.Sqrt2, lower) {
+			t.Errorf("InvErfc(%g)*-sqrt(2) == %.17g, want %.17g", 2*p, got*-math.Sqrt2, lower)
+		}
+		if got := NormalQuantileLog(math.Log(p)); !relativeEq(got, lower) {
+			t.Errorf("NormalQuantileLog(log(%g)) == %.17g, want %.17g", p, got, lower)
+		}
+		if got := NormalQuantileLog(math.Log1p(-p)); math.Abs(got-upper) > 1e-12*math.Abs(upper) {
+			t.Errorf("NormalQuantileLog(log1p(-%g)) == %.17g, want %.17g", p, got, upper)
+		}
+	}
+}
+
+// Reference values computed with 80 digit arithmetic.
+func TestNormalQuantileLog(t *testing.T) {
+	tests := []struct {
+		logP, want float64
+	}{
+		{-10000, -141.37983987312717},
+		{-700, -37.295079632647415},
+		{-100, -13.888476033003887},
+		{-3, -1.6469217205277147},
+		{-0.1, 1.3096177994584932},
+		{-1e-10, 6.3613409024117349},
+		{-1e-300, 37.047096299361201},
+	}
+	for _, tt := range tests {
+		if got := NormalQuantileLog(tt.logP); !relativeEq(got, tt.want) {
+			t.Errorf("NormalQuantileLog(%g) == %.17g, want %.17g", tt.logP, got, tt.want)
+		}
+	}
+	if got := NormalQuantileLog(0); !math.IsInf(got, 1) {
+		t.Errorf("NormalQuantileLog(0) == %v, want +Inf", got)
+	}
+	if got := NormalQuantileLog(math.Inf(-1)); !math.IsInf(got, -1) {
+		t.Errorf("NormalQuantileLog(-Inf) == %v, want -Inf", got)
+	}
+	if got := NormalQuantileLog(0.1); !math.IsNaN(got) {
+		t.Errorf("NormalQuantileLog(0.1) == %v, want NaN", got)
+	}
+}
+
+// Reference values computed with 80 digit arithmetic.
+func TestInvErf(t *testing.T) {
+	tests := []struct {
+		x, want float64
+	}{
+		{1e-12, 8.8622692545275799e-13},
+		{-1e-12, -8.8622692545275799e-13},
+		{0.1, 0.088855990494257686},
+		{0.5, 0.47693627620446988},
+		{0.8, 0.90619380243682335},
+		{0.9, 1.1630871536766743},
+		{0.99, 1.8213863677184494},
+		{0.999999, 3.4589107372754988},
+		{-0.999999, -3.4589107372754988},
+		{0, 0},
+		{1, math.Inf(1)},
+		{-1, math.Inf(-1)},
+	}
+	for _, tt := range tests {
+		if got := InvErf(tt.x); got != tt.want && !relativeEq(got, tt.want) {
+			t.Errorf("InvErf(%g) == %.17g, want %.17g", tt.x, got, tt.want)
+		}
+	}
+	if got := InvErf(1.5); !math.IsNaN(got) {
+		t.Errorf("InvErf(1.5) == %v, want NaN", got)
+	}
+}
+
+func TestInvErfc_Tail(t *testing.T) {
+	// InvErfc(2p) = -NormalQuantile(p)/sqrt(2), reference values from
+	// normalQuantileTests.
+	for _, tt := range normalQuantileTests {
+		if tt.p >= 0.5 {
+			continue
+		}
+		want := -tt.want / math.Sqrt2
+		if got := InvErfc(2 * tt.p); !relativeEq(got, want) {
+			t.Errorf("InvErfc(%g) == %.17g, want %.17g", 2*tt.p, got, want)
+		}
+	}
+	want := 38.467405617144344 / math.Sqrt2
+	if got := InvErfc(2 * math.SmallestNonzeroFloat64); !relativeEq(got, want) {
+		t.Errorf("InvErfc(%g) == %.17g, want %.17g", 2*math.SmallestNonzeroFloat64, got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package trueskill
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxOutcomePlayers is the largest number of players for which all possible
+// outcomes are enumerated, the number of outcomes grows faster than n!.
+const maxOutcomePlayers = 5
+
+var (
+	errTooManyOutcomePlayers = fmt.Errorf("outcomes can be enumerated for at most %d players", maxOutcomePlayers)
+	errTooFewOutcomePlayers  = errors.New("outcomes require at least two players")
+)
+
+// Outcome is a possible result of a match between players.
+type Outcome struct {
+	Ranking []int  // Player indexes ordered from first to last place
+	Draws   []bool // Draws[i] reports whether Ranking[i] and Ranking[i+1] drew
+}
+
+func (o Outcome) String() string {
+	s := fmt.Sprint(o.Ranking[0])
+	for i, draw := range o.Draws {
+		sep := " > "
+		if draw {
+			sep = " = "
+		}
+		s += sep + fmt.Sprint(o.Ranking[i+1])
+	}
+	return s
+}
+
+// outcomes returns every possible outcome of a match between n players.
+// Players that draw are ordered by index so that every outcome is listed
+// once. Draws are only included when the draw probability is non-zero.
+func (ts Config) outcomes(n int) ([]Outcome, error) {
+	switch {
+	case n < 2:
+		return nil, errTooFewOutcomePlayers
+	case n > maxOutcomePlayers:
+		return nil, errTooManyOutcomePlayers
+	}
+
+	var outcomes []Outcome
+	var ranking []int
+	var draws []bool
+	var place func(remaining int)
+	place = func(remaining int) {
+		if remaining == 0 {
+			outcomes = append(outcomes, Outcome{
+				Ranking: append([]int(nil), ranking...),
+				Draws:   append([]bool(nil), draws[1:]...),
+			})
+			return
+		}
+		// Every non-empty subset of the remaining players can share the
+		// next place.
+		for group := 1; group <= remaining; group++ {
+			if group&remaining != group {
+				continue
+			}
+			size := 0
+			for i := 0; i < n; i++ {
+				if group&(1<<uint(i)) != 0 {
+					ranking = append(ranking, i)
+					draws = append(draws, size > 0)
+					size++
+				}
+			}
+			if size == 1 || ts.drawProbability > 0 {
+				place(remaining &^ group)
+			}
+			ranking = ranking[:len(ranking)-size]
+			draws = draws[:len(draws)-size]
+		}
+	}
+	// The first place is never a draw with a previous player, draws[0] is
+	// a placeholder that is dropped from the outcome.
+	place(1<<uint(n) - 1)
+
+	return outcomes, nil
+}
+
+// adjustSkillsForOutcome rates the players as if the outcome happened. The
+// new skills are returned in the order of players.
+func (ts Config) adjustSkillsForOutcome(players []Player, o Outcome) (newSkills []Player, probability float64) {
+	ranked := make([]Player, len(players))
+	for k, i := range o.Ranking {
+		ranked[k] = players[i]
+	}
+
+	rankedSkills, probability := ts.AdjustSkillsWithDraws(ranked, o.Draws)
+
+	newSkills = make([]Player, len(players))
+	for k, i := range o.Ranking {
+		newSkills[i] = rankedSkills[k]
+	}
+	return newSkills, probability
+}
+
+// rateMatchForOutcome works like adjustSkillsForOutcome for a match with
+// options.
+func (ts Config) rateMatchForOutcome(players []Player, mc matchConfig, o Outcome) (newSkills []Player, probability float64) {
+	mps := mc.matchPlayers(players)
+	ranked := make([]Player, len(players))
+	rankedMps := make([]matchPlayer, len(players))
+	for k, i := range o.Ranking {
+		ranked[k] = players[i]
+		rankedMps[k] = mps[i]
+	}
+
+	r, _ := ts.rateMatch(ranked, rankedMps, o.Draws, mc.advantage)
+
+	newSkills = make([]Player, len(players))
+	for k, i := range o.Ranking {
+		newSkills[i] = r.Players[k]
+	}
+	return newSkills, r.Probability()
+}
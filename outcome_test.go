@@ -0,0 +1,39 @@
+package trueskill
+
+import "testing"
+
+func TestOutcomes(t *testing.T) {
+	ts := New()
+	for _, tt := range []struct {
+		n    int
+		want int
+	}{
+		{2, 3},
+		{3, 13},
+		{4, 75},
+	} {
+		outcomes, err := ts.outcomes(tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(outcomes) != tt.want {
+			t.Errorf("len(outcomes(%d)) == %d, want %d", tt.n, len(outcomes), tt.want)
+		}
+		seen := make(map[string]bool)
+		for _, o := range outcomes {
+			if seen[o.String()] {
+				t.Errorf("outcomes(%d) has duplicate %s", tt.n, o)
+			}
+			seen[o.String()] = true
+		}
+	}
+
+	outcomes, _ := New(DrawProbabilityZero()).outcomes(3)
+	if len(outcomes) != 6 {
+		t.Errorf("len(outcomes(3)) without draws == %d, want 6", len(outcomes))
+	}
+
+	if _, err := ts.outcomes(maxOutcomePlayers + 1); err == nil {
+		t.Error("outcomes() for too many players did not return an error")
+	}
+}
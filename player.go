@@ -2,11 +2,17 @@ package trueskill
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mafredri/go-trueskill/gaussian"
 )
 
 // Player is a player with a certain skill (mu, sigma).
+//
+// Player implements the text, JSON, binary and database/sql encodings. A
+// struct that embeds Player promotes these methods and is encoded as the
+// player alone, its other fields are dropped. Use a named field instead of
+// embedding to encode the struct field by field.
 type Player struct {
 	gaussian.Gaussian
 }
@@ -32,3 +38,39 @@ func (p Player) Sigma() float64 {
 func (p Player) String() string {
 	return fmt.Sprintf("Player(mu=%.3f sigma=%.3f)", p.Mu(), p.Sigma())
 }
+
+// IdentifiedPlayer is a player that carries its identity and rating history
+// alongside its skill. It can be passed through the rating functions of
+// Config in place of Player when the caller needs to know whose skill was
+// returned.
+type IdentifiedPlayer struct {
+	Player
+	ID          string    // Caller defined identifier
+	GamesPlayed int       // Number of rated games played
+	LastPlayed  time.Time // Time of the last rated game, zero if none
+	Provisional bool      // True until enough games have been played
+}
+
+// NewIdentifiedPlayer returns an identified player with the provided id and
+// skill. The player has not played any games and is provisional, see
+// Config.NewIdentifiedPlayer for a player that follows the configured number
+// of provisional games.
+func NewIdentifiedPlayer(id string, p Player) IdentifiedPlayer {
+	return IdentifiedPlayer{
+		Player:      p,
+		ID:          id,
+		Provisional: true,
+	}
+}
+
+func (p IdentifiedPlayer) String() string {
+	return fmt.Sprintf("Player(id=%s mu=%.3f sigma=%.3f games=%d)", p.ID, p.Mu(), p.Sigma(), p.GamesPlayed)
+}
+
+func identifiedPlayerSkills(players []IdentifiedPlayer) []Player {
+	skills := make([]Player, len(players))
+	for i, p := range players {
+		skills[i] = p.Player
+	}
+	return skills
+}
@@ -0,0 +1,83 @@
+package trueskill
+
+import "math"
+
+// SkillChange describes how the skill of a player changes from a match.
+type SkillChange struct {
+	Before         Player
+	After          Player
+	MuDelta        float64 // After.Mu() - Before.Mu()
+	SigmaDelta     float64 // After.Sigma() - Before.Sigma()
+	TrueSkillDelta float64 // Change in conservative TrueSkill, see Config.TrueSkill
+}
+
+func (ts Config) skillChange(before, after Player) SkillChange {
+	return SkillChange{
+		Before:         before,
+		After:          after,
+		MuDelta:        after.Mu() - before.Mu(),
+		SigmaDelta:     after.Sigma() - before.Sigma(),
+		TrueSkillDelta: ts.TrueSkill(after) - ts.TrueSkill(before),
+	}
+}
+
+// OutcomePreview is the effect a possible outcome of a match would have on
+// the skills of the players.
+type OutcomePreview struct {
+	Outcome
+	Probability float64       // Predicted probability of the outcome
+	Changes     []SkillChange // Skill change of each player, in player order
+}
+
+// PreviewOutcomes returns the skill changes for every possible outcome of a
+// match between the players: a win, a loss and a draw for a head-to-head
+// match and every placement (including draws) for a free-for-all. Draws are
+// omitted when the draw probability is zero. At most five players are
+// supported.
+//
+// The probabilities of the outcomes sum to one. The match can be configured
+// with the same options as Rate, e.g. a Handicap changes both the predicted
+// probabilities and the skill changes.
+func (ts Config) PreviewOutcomes(players []Player, opts ...MatchOption) ([]OutcomePreview, error) {
+	outcomes, err := ts.outcomes(len(players))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPlayers(players); err != nil {
+		return nil, err
+	}
+	mc, err := newMatchConfig(len(players), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var previews []OutcomePreview
+	var total float64
+	for _, o := range outcomes {
+		var newSkills []Player
+		var probability float64
+		if len(opts) == 0 {
+			newSkills, probability = ts.adjustSkillsForOutcome(players, o)
+		} else {
+			newSkills, probability = ts.rateMatchForOutcome(players, mc, o)
+		}
+		if probability == 0 || math.IsNaN(probability) {
+			continue
+		}
+
+		preview := OutcomePreview{Outcome: o, Probability: probability}
+		for i, p := range players {
+			preview.Changes = append(preview.Changes, ts.skillChange(p, newSkills[i]))
+		}
+		previews = append(previews, preview)
+		total += probability
+	}
+
+	// The probabilities of all outcomes sum to one for two players, with
+	// more players the approximation is normalised.
+	for i := range previews {
+		previews[i].Probability /= total
+	}
+
+	return previews, nil
+}
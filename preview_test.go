@@ -0,0 +1,82 @@
+package trueskill
+
+import (
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestPreviewOutcomes_HeadToHead(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer()}
+
+	previews, err := ts.PreviewOutcomes(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != 3 {
+		t.Fatalf("len(PreviewOutcomes()) == %d, want 3", len(previews))
+	}
+
+	// The win matches TestTrueSkill_HeadToHead and the draw matches
+	// TestTrueSkill_HeadToHead_Draw.
+	win, lose, draw := previews[0], previews[1], previews[2]
+	if win.String() != "0 > 1" || lose.String() != "1 > 0" || draw.String() != "0 = 1" {
+		t.Fatalf("outcomes == %s, %s, %s, want 0 > 1, 1 > 0, 0 = 1", win, lose, draw)
+	}
+	testPlayerSkills(t, []Player{win.Changes[0].After, win.Changes[1].After}, []float64{
+		29.3958320199992000, 7.1714755873261900,
+		20.6041679800008000, 7.1714755873261900,
+	})
+	testPlayerSkills(t, []Player{draw.Changes[0].After, draw.Changes[1].After}, []float64{
+		25.0000000000000000, 6.4575196623173100,
+		25.0000000000000000, 6.4575196623173100,
+	})
+
+	if !mathextra.Float64AlmostEq(win.Changes[0].MuDelta, lose.Changes[1].MuDelta, defaultEpsilon) {
+		t.Errorf("MuDelta of winner %v differs between outcomes, %v", win.Changes[0].MuDelta, lose.Changes[1].MuDelta)
+	}
+	if win.Changes[0].MuDelta <= 0 || win.Changes[1].MuDelta >= 0 {
+		t.Errorf("win MuDeltas == %v, %v, want positive, negative", win.Changes[0].MuDelta, win.Changes[1].MuDelta)
+	}
+	c := win.Changes[0]
+	if want := ts.TrueSkill(c.After) - ts.TrueSkill(c.Before); c.TrueSkillDelta != want {
+		t.Errorf("TrueSkillDelta == %v, want %v", c.TrueSkillDelta, want)
+	}
+	if want := c.After.Sigma() - c.Before.Sigma(); c.SigmaDelta != want {
+		t.Errorf("SigmaDelta == %v, want %v", c.SigmaDelta, want)
+	}
+
+	var total float64
+	for _, p := range previews {
+		total += p.Probability
+	}
+	if !mathextra.Float64AlmostEq(total, 1, 1e-12) {
+		t.Errorf("sum of probabilities == %v, want 1", total)
+	}
+	if !mathextra.Float64AlmostEq(win.Probability, lose.Probability, 1e-12) {
+		t.Errorf("win probability %v differs from loss probability %v", win.Probability, lose.Probability)
+	}
+}
+
+func TestPreviewOutcomes_FreeForAll(t *testing.T) {
+	ts := New(DrawProbabilityZero())
+	players := []Player{ts.NewPlayer(), NewPlayer(30, 4), NewPlayer(20, 6)}
+
+	previews, err := ts.PreviewOutcomes(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previews) != 6 {
+		t.Fatalf("len(PreviewOutcomes()) == %d, want 6", len(previews))
+	}
+	for _, p := range previews {
+		first := p.Ranking[0]
+		if p.Changes[first].MuDelta <= 0 {
+			t.Errorf("%s: winner MuDelta == %v, want > 0", p.Outcome, p.Changes[first].MuDelta)
+		}
+		if p.Changes[first].Before != players[first] {
+			t.Errorf("%s: Changes not in player order", p.Outcome)
+		}
+	}
+}
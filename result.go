@@ -0,0 +1,197 @@
+package trueskill
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mafredri/go-trueskill/collection"
+	"github.com/mafredri/go-trueskill/factor"
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+var (
+	errTooFewPlayers   = errors.New("at least two players are required")
+	errRatingNotFinite = errors.New("rating produced a skill that is not finite or not proper")
+)
+
+// Result is the outcome of rating a match together with the diagnostics of
+// the inference that produced it.
+type Result struct {
+	// Players holds the new skill of every player.
+	Players []Player
+
+	// Changes holds the skill change of every player.
+	Changes []SkillChange
+
+	// LogEvidence is the natural logarithm of the probability of the
+	// outcome given the skills before the match.
+	LogEvidence float64
+
+	// Performances holds the posterior marginal of the performance of
+	// every player.
+	Performances []gaussian.Gaussian
+
+	// PerformanceDifferences holds the posterior marginal of the
+	// performance difference between Players[i] and Players[i+1].
+	PerformanceDifferences []gaussian.Gaussian
+
+	// LoopIterations is the number of forward-backward iterations run
+	// over the performance differences, zero for a head-to-head match
+	// where the factor graph has no loop.
+	LoopIterations int
+
+	// LoopDelta is the largest change of any marginal in the final loop
+	// iteration, at most the desired accuracy of the loop schedule.
+	LoopDelta float64
+
+	// Advantage holds the posterior of the advantage of the match, the
+	// zero value if the match has no SideAdvantage.
+	Advantage Advantage
+}
+
+// Probability returns the probability of the outcome given the skills
+// before the match, the same value returned by AdjustSkillsWithDraws.
+func (r Result) Probability() float64 {
+	return math.Exp(r.LogEvidence)
+}
+
+// Rate rates a match like AdjustSkillsWithDraws but returns a Result with
+// the diagnostics of the inference. An error is returned instead of a panic
+// if the number of players or draws is invalid.
+//
+// The skill of every player must be a proper gaussian, see
+// gaussian.Gaussian.IsProper. An error is also returned if the inference
+// produces a skill that is not finite or not proper, or if a factor of the
+// inference fails, so that a numerical failure never replaces the stored
+// rating of a player.
+//
+// The match can be configured with options, e.g. SideAdvantage, Handicap or
+// Anchor. The factor graph of a match with options is scheduled by
+// factor.Schedule instead of the schedule used for a plain match.
+func (ts Config) Rate(players []Player, draws []bool, opts ...MatchOption) (Result, error) {
+	if len(players) < 2 {
+		return Result{}, errTooFewPlayers
+	}
+	if len(draws) != len(players)-1 {
+		return Result{}, fmt.Errorf("draws slice should have length %d but have %d instead", len(players)-1, len(draws))
+	}
+	if err := checkPlayers(players); err != nil {
+		return Result{}, err
+	}
+
+	var r Result
+	if len(opts) == 0 {
+		var err error
+		if r, err = ts.rate(players, draws); err != nil {
+			return Result{}, err
+		}
+	} else {
+		mc, err := newMatchConfig(len(players), opts)
+		if err != nil {
+			return Result{}, err
+		}
+		if r, err = ts.rateMatch(players, mc.matchPlayers(players), draws, mc.advantage); err != nil {
+			return Result{}, err
+		}
+		if mc.advantageSide != -1 && !r.Advantage.IsProper() {
+			return Result{}, errRatingNotFinite
+		}
+	}
+	for _, p := range r.Players {
+		if !p.IsProper() {
+			return Result{}, errRatingNotFinite
+		}
+	}
+	if math.IsNaN(r.LogEvidence) {
+		return Result{}, errRatingNotFinite
+	}
+
+	return r, nil
+}
+
+// checkPlayers returns an error if the skill of any player is not a proper
+// gaussian.
+func checkPlayers(players []Player) error {
+	for i, p := range players {
+		if err := p.Check(); err != nil {
+			return fmt.Errorf("player %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// rate rates a match on the hand written schedule. The error of the first
+// factor that failed is returned alongside the result, see factor.List.Err.
+func (ts Config) rate(players []Player, draws []bool) (Result, error) {
+	inf := ts.infer(players, draws)
+
+	r := Result{
+		LoopIterations: inf.loop.iterations,
+		LoopDelta:      inf.loop.delta,
+	}
+	for i, id := range inf.skillIndex {
+		p := Player{Gaussian: inf.varBag.Get(id)}
+		r.Players = append(r.Players, p)
+		r.Changes = append(r.Changes, ts.skillChange(players[i], p))
+	}
+	for _, id := range inf.skillFactors.playerPerformances {
+		r.Performances = append(r.Performances, inf.varBag.Get(id))
+	}
+	for _, id := range inf.skillFactors.playerPerformanceDifferences {
+		r.PerformanceDifferences = append(r.PerformanceDifferences, inf.varBag.Get(id))
+	}
+
+	r.LogEvidence = inf.factorList.LogNormalization()
+
+	return r, inf.factorList.Err()
+}
+
+// inference is the state of the factor graph of a match after running its
+// schedule.
+type inference struct {
+	varBag       *collection.DistributionBag
+	skillFactors skillFactors
+	skillIndex   []int
+	factorList   factor.List
+	schedule     schedule.Runner
+	loop         *loopStats
+}
+
+func (ts Config) infer(players []Player, draws []bool) inference {
+	// TODO: Rewrite the distribution bag and simplify the factor list as well
+	prior := gaussian.NewFromPrecision(0, 0)
+	varBag := collection.NewDistributionBag(prior)
+
+	skillFactors, skillIndex, factorList := buildSkillFactors(ts, players, draws, varBag)
+
+	sched := buildSkillFactorSchedule(len(players), skillFactors, loopMaxDelta)
+
+	// delta
+	loop := new(loopStats)
+	_ = schedule.Run(sched, -1, schedule.Trace(loop))
+
+	return inference{
+		varBag:       varBag,
+		skillFactors: skillFactors,
+		skillIndex:   skillIndex,
+		factorList:   factorList,
+		schedule:     sched,
+		loop:         loop,
+	}
+}
+
+// loopStats records the progress of the loop schedule, it traces the
+// schedule of a match which has at most one loop.
+type loopStats struct {
+	iterations int
+	delta      float64
+}
+
+func (l *loopStats) Step(depth, input int, label string, delta float64) {}
+
+func (l *loopStats) LoopIteration(depth, iteration int, delta float64) {
+	l.iterations = iteration
+	l.delta = delta
+}
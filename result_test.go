@@ -0,0 +1,148 @@
+package trueskill
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestRate_4PFreeForAll(t *testing.T) {
+	ts := New()
+	players := []Player{ts.NewPlayer(), ts.NewPlayer(), ts.NewPlayer(), ts.NewPlayer()}
+
+	r, err := ts.Rate(players, []bool{false, false, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSkills, wantProbability := ts.AdjustSkills(players, false)
+	for i := range players {
+		if r.Players[i] != wantSkills[i] {
+			t.Errorf("Players[%d] == %v, want %v", i, r.Players[i], wantSkills[i])
+		}
+		if want := wantSkills[i].Mu() - players[i].Mu(); r.Changes[i].MuDelta != want {
+			t.Errorf("Changes[%d].MuDelta == %v, want %v", i, r.Changes[i].MuDelta, want)
+		}
+	}
+	if !mathextra.Float64AlmostEq(r.Probability(), wantProbability, 1e-15) {
+		t.Errorf("Probability() == %v, want %v", r.Probability(), wantProbability)
+	}
+	if !mathextra.Float64AlmostEq(r.LogEvidence, math.Log(wantProbability), 1e-12) {
+		t.Errorf("LogEvidence == %v, want %v", r.LogEvidence, math.Log(wantProbability))
+	}
+
+	if len(r.Performances) != 4 || len(r.PerformanceDifferences) != 3 {
+		t.Fatalf("got %d performances and %d differences, want 4 and 3", len(r.Performances), len(r.PerformanceDifferences))
+	}
+	for i, d := range r.PerformanceDifferences {
+		if d.Mean() <= 0 {
+			t.Errorf("PerformanceDifferences[%d].Mean() == %v, want > 0", i, d.Mean())
+		}
+		if want := r.Performances[i].Mean() - r.Performances[i+1].Mean(); !mathextra.Float64AlmostEq(d.Mean(), want, 1e-3) {
+			t.Errorf("PerformanceDifferences[%d].Mean() == %v, want %v", i, d.Mean(), want)
+		}
+	}
+
+	if r.LoopIterations < 2 {
+		t.Errorf("LoopIterations == %d, want at least 2", r.LoopIterations)
+	}
+	if r.LoopDelta > loopMaxDelta {
+		t.Errorf("LoopDelta == %v, want at most %v", r.LoopDelta, loopMaxDelta)
+	}
+}
+
+func TestRate_HeadToHead(t *testing.T) {
+	ts := New()
+	r, err := ts.Rate([]Player{ts.NewPlayer(), ts.NewPlayer()}, []bool{false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPlayerSkills(t, r.Players, []float64{
+		29.3958320199992000, 7.1714755873261900,
+		20.6041679800008000, 7.1714755873261900,
+	})
+	if r.LoopIterations != 0 {
+		t.Errorf("LoopIterations == %d, want 0", r.LoopIterations)
+	}
+}
+
+func TestRate_Errors(t *testing.T) {
+	ts := New()
+	if _, err := ts.Rate([]Player{ts.NewPlayer()}, nil); err == nil {
+		t.Error("Rate() with one player did not return an error")
+	}
+	if _, err := ts.Rate([]Player{ts.NewPlayer(), ts.NewPlayer()}, nil); err == nil {
+		t.Error("Rate() with missing draws did not return an error")
+	}
+
+	improper := []Player{
+		{Gaussian: gaussian.NewFromPrecision(0, 0)},
+		{Gaussian: gaussian.NewFromPrecision(1, -1)},
+		{Gaussian: gaussian.NewFromPrecision(math.NaN(), 1)},
+	}
+	for _, p := range improper {
+		if _, err := ts.Rate([]Player{ts.NewPlayer(), p}, []bool{false}); err == nil {
+			t.Errorf("Rate() with %#v did not return an error", p.Gaussian)
+		}
+	}
+}
+
+func TestRate_LargeFreeForAll(t *testing.T) {
+	ts := New()
+	var players []Player
+	for i := 100; i > 0; i-- {
+		players = append(players, NewPlayer(float64(i), 2))
+	}
+	draws := make([]bool, len(players)-1)
+
+	r, err := ts.Rate(players, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(r.Players); i++ {
+		if r.Players[i].Mu() >= r.Players[i-1].Mu() {
+			t.Errorf("Players[%d].Mu() == %v, want less than %v", i, r.Players[i].Mu(), r.Players[i-1].Mu())
+		}
+	}
+}
+
+func TestRate_TruncationFails(t *testing.T) {
+	ts := New()
+	players := []Player{NewPlayer(0, 1), NewPlayer(1e10, 1)}
+
+	// The upset is too extreme for the truncation to be represented, the
+	// rating must not be returned unchanged as if it succeeded.
+	if _, err := ts.Rate(players, []bool{false}); err == nil {
+		t.Error("Rate() did not return an error")
+	}
+	if _, err := ts.Rate(players, []bool{false}, Handicap(0, 1)); err == nil {
+		t.Error("Rate() with options did not return an error")
+	}
+}
+
+func TestRate_ExtremeUpset(t *testing.T) {
+	ts := New()
+	players := []Player{NewPlayer(0, 1), NewPlayer(50, 1)}
+
+	r, err := ts.Rate(players, []bool{false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range r.Players {
+		if math.IsNaN(p.Mu()) || math.IsInf(p.Mu(), 0) || !(p.Sigma() > 0) {
+			t.Errorf("Players[%d] == %v, want finite skill", i, p)
+		}
+	}
+	if r.Players[0].Mu() <= players[0].Mu() || r.Players[1].Mu() >= players[1].Mu() {
+		t.Errorf("Players == %v, want the winner to gain and the loser to lose", r.Players)
+	}
+
+	// The skills are widened by the dynamics factor tau before the match.
+	c := math.Sqrt(2*(1+ts.Tau()*ts.Tau()) + 2*ts.Beta()*ts.Beta())
+	want := gaussian.LogNormCdf((players[0].Mu() - players[1].Mu() - ts.DrawMargin(2)) / c)
+	if !mathextra.Float64AlmostEq(r.LogEvidence/want, 1, 1e-9) {
+		t.Errorf("LogEvidence == %v, want %v", r.LogEvidence, want)
+	}
+}
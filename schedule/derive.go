@@ -0,0 +1,243 @@
+package schedule
+
+// Factor describes a factor of a factor graph for Derive.
+type Factor struct {
+	Label     string              // Label of the steps updating the factor
+	Variables []int               // Variable of every message from the factor
+	Update    func(i int) float64 // Updates the message to Variables[i]
+
+	// Approximate is set when the message to a variable depends on the
+	// message from the same variable, as with expectation propagation
+	// where the marginal of the variable is approximated.
+	Approximate bool
+}
+
+// message is a message from a factor to one of its variables.
+type message struct {
+	factor, input int
+}
+
+// Derive derives a schedule for a factor graph. The message from a factor
+// to a variable depends on the messages sent to the other variables of the
+// factor (and to the same variable for approximate factors). Messages
+// without circular dependencies, such as those of a tree, are updated once
+// in the order of their dependencies. Messages in a loop are updated in a
+// forward and a backward pass until no marginal changes by more than
+// maxDelta.
+//
+// Where the order is otherwise free the factors are updated in the order
+// they are listed, so priors should be listed first.
+func Derive(factors []Factor, maxDelta float64) Runner {
+	var msgs []message
+	for f, fac := range factors {
+		for i := range fac.Variables {
+			msgs = append(msgs, message{f, i})
+		}
+	}
+
+	// Messages sent to every variable.
+	toVar := make(map[int][]int)
+	for m, msg := range msgs {
+		v := factors[msg.factor].Variables[msg.input]
+		toVar[v] = append(toVar[v], m)
+	}
+
+	// deps[m] are the messages m depends on and users[m] the messages that
+	// depend on m.
+	deps := make([][]int, len(msgs))
+	users := make([][]int, len(msgs))
+	for m, msg := range msgs {
+		fac := factors[msg.factor]
+		for i, v := range fac.Variables {
+			if i == msg.input && !fac.Approximate {
+				continue
+			}
+			for _, d := range toVar[v] {
+				if msgs[d].factor != msg.factor {
+					deps[m] = append(deps[m], d)
+					users[d] = append(users[d], m)
+				}
+			}
+		}
+	}
+
+	sccs := stronglyConnected(users)
+
+	step := func(m int) Runner {
+		fac := factors[msgs[m].factor]
+		return NewLabeledStep(fac.Label, fac.Update, msgs[m].input)
+	}
+
+	var runners []Runner
+	for _, scc := range orderComponents(sccs, deps) {
+		if len(scc) == 1 {
+			runners = append(runners, step(scc[0]))
+			continue
+		}
+
+		forward := loopOrder(scc, deps, func(m int) bool { return factors[msgs[m].factor].Approximate })
+		var forwardSteps, backwardSteps []Runner
+		for i := range forward {
+			forwardSteps = append(forwardSteps, step(forward[i]))
+			backwardSteps = append(backwardSteps, step(forward[len(forward)-1-i]))
+		}
+		runners = append(runners, NewLoop(NewSequence(NewSequence(forwardSteps...), NewSequence(backwardSteps...)), maxDelta))
+	}
+
+	return NewSequence(runners...)
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// with the edges m -> edges[m] (Tarjan's algorithm).
+func stronglyConnected(edges [][]int) [][]int {
+	n := len(edges)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var (
+		next  int
+		stack []int
+		sccs  [][]int
+		visit func(v int)
+	)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if index[w] < 0 {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+
+		if low[v] == index[v] {
+			var scc []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			visit(v)
+		}
+	}
+
+	return sccs
+}
+
+// orderComponents orders the strongly connected components so that every
+// component comes after the components it depends on. When several
+// components are ready the one with the lowest message comes first.
+func orderComponents(sccs [][]int, deps [][]int) [][]int {
+	component := make(map[int]int)
+	first := make([]int, len(sccs))
+	for c, scc := range sccs {
+		first[c] = scc[0]
+		for _, m := range scc {
+			component[m] = c
+			if m < first[c] {
+				first[c] = m
+			}
+		}
+	}
+
+	waiting := make([]map[int]bool, len(sccs))
+	for c, scc := range sccs {
+		waiting[c] = make(map[int]bool)
+		for _, m := range scc {
+			for _, d := range deps[m] {
+				if dc := component[d]; dc != c {
+					waiting[c][dc] = true
+				}
+			}
+		}
+	}
+
+	done := make([]bool, len(sccs))
+	var order [][]int
+	for len(order) < len(sccs) {
+		next := -1
+		for c := range sccs {
+			if !done[c] && len(waiting[c]) == 0 && (next < 0 || first[c] < first[next]) {
+				next = c
+			}
+		}
+		done[next] = true
+		order = append(order, sccs[next])
+		for c := range waiting {
+			delete(waiting[c], next)
+		}
+	}
+
+	return order
+}
+
+// loopOrder orders the messages of a loop so that as many messages as
+// possible are updated after the messages they depend on. When every
+// remaining message waits for another the one waiting for the fewest is
+// picked, preferring exact messages over approximate ones since they need
+// an informative message from their variable.
+func loopOrder(scc []int, deps [][]int, approximate func(m int) bool) []int {
+	inLoop := make(map[int]bool)
+	for _, m := range scc {
+		inLoop[m] = true
+	}
+	done := make(map[int]bool)
+	waitingFor := func(m int) int {
+		var n int
+		for _, d := range deps[m] {
+			if inLoop[d] && !done[d] {
+				n++
+			}
+		}
+		return n
+	}
+
+	var order []int
+	for len(order) < len(scc) {
+		next, nextWaiting := -1, 0
+		better := func(m, w int) bool {
+			switch {
+			case next < 0:
+				return true
+			case w != nextWaiting:
+				return w < nextWaiting
+			case w > 0 && approximate(m) != approximate(next):
+				return !approximate(m)
+			default:
+				return m < next
+			}
+		}
+		for _, m := range scc {
+			if done[m] {
+				continue
+			}
+			if w := waitingFor(m); better(m, w) {
+				next, nextWaiting = m, w
+			}
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+
+	return order
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDerive(t *testing.T) {
+	var updates []string
+	update := func(label string) func(i int) float64 {
+		return func(i int) float64 {
+			updates = append(updates, label+string('0'+rune(i)))
+			return 0
+		}
+	}
+
+	// Variables 0 and 1 have priors and are connected through variable 2,
+	// which is observed by an approximate factor.
+	tree := []Factor{
+		{Label: "obs", Variables: []int{2}, Update: update("obs"), Approximate: true},
+		{Label: "sum", Variables: []int{2, 0, 1}, Update: update("sum")},
+		{Label: "prior", Variables: []int{0}, Update: update("prior")},
+		{Label: "prior", Variables: []int{1}, Update: update("prior")},
+	}
+	Run(Derive(tree, 0), -1)
+	want := []string{"prior0", "prior0", "sum0", "obs0", "sum1", "sum2"}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("tree updates == %v, want %v", updates, want)
+	}
+
+	// Two approximate factors observing the same variable depend on each
+	// other and are updated in a loop.
+	updates = nil
+	loopy := append(tree, Factor{Label: "obs2", Variables: []int{2}, Update: update("obs2"), Approximate: true})
+	sched := Derive(loopy, 0)
+	Run(sched, -1)
+	want = []string{
+		"prior0", "prior0", "sum0",
+		"obs0", "obs20", "obs20", "obs0", // loop converges in one iteration
+		"sum1", "sum2",
+	}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("loopy updates == %v, want %v", updates, want)
+	}
+	n := Describe(sched)
+	if len(n.Children) != 6 || n.Children[3].Kind != KindLoop {
+		t.Errorf("Describe(Derive(loopy)) == %+v, want a loop as the fourth child", n)
+	}
+}
@@ -0,0 +1,107 @@
+package schedule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Kinds of schedule nodes.
+const (
+	KindStep     = "step"
+	KindSequence = "sequence"
+	KindParallel = "parallel"
+	KindLoop     = "loop"
+	KindRunner   = "runner" // A Runner not provided by this package
+)
+
+// Node describes a schedule, it is a tree of steps, sequences and loops.
+type Node struct {
+	Kind     string  `json:"kind"`
+	Label    string  `json:"label,omitempty"`    // Label of a step
+	Input    int     `json:"input"`              // Input of a step
+	MaxDelta float64 `json:"maxDelta,omitempty"` // Desired accuracy of a loop
+	Children []Node  `json:"children,omitempty"` // Schedules run by a sequence, parallel or loop
+}
+
+// Describer is implemented by Runners that can describe themselves, for
+// example a Runner wrapping one of the schedules provided by this package.
+type Describer interface {
+	Describe() Node
+}
+
+// Describe returns a description of the schedule. Runners not provided by
+// this package are described by their Describe method when they implement
+// Describer, otherwise as an opaque node of kind KindRunner.
+func Describe(schedule Runner) Node {
+	switch s := schedule.(type) {
+	case step:
+		return Node{Kind: KindStep, Label: s.label, Input: s.input}
+	case sequence:
+		n := Node{Kind: KindSequence}
+		for _, c := range s.sequences {
+			n.Children = append(n.Children, Describe(c))
+		}
+		return n
+	case parallel:
+		n := Node{Kind: KindParallel}
+		for _, c := range s.sequences {
+			n.Children = append(n.Children, Describe(c))
+		}
+		return n
+	case loop:
+		return Node{Kind: KindLoop, MaxDelta: s.maxDelta, Children: []Node{Describe(s.schedule)}}
+	case Describer:
+		return s.Describe()
+	default:
+		return Node{Kind: KindRunner, Label: fmt.Sprintf("%T", schedule)}
+	}
+}
+
+// WriteDOT writes the schedule as a Graphviz DOT digraph. Children are
+// ordered from left to right in the order they are run.
+func (n Node) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph schedule {")
+	fmt.Fprintln(bw, "\tordering=out;")
+	var id int
+	n.writeDOT(bw, &id)
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func (n Node) writeDOT(w io.Writer, id *int) int {
+	self := *id
+	*id++
+
+	var label, shape string
+	switch n.Kind {
+	case KindStep:
+		label = n.Label
+		if label == "" {
+			label = "step"
+		}
+		label += " (" + strconv.Itoa(n.Input) + ")"
+		shape = "box"
+	case KindLoop:
+		label = "loop (Δ ≤ " + strconv.FormatFloat(n.MaxDelta, 'g', -1, 64) + ")"
+		shape = "doublecircle"
+	case KindSequence, KindParallel:
+		label = n.Kind
+		shape = "circle"
+	default:
+		label = n.Kind
+		if n.Label != "" {
+			label += ": " + n.Label
+		}
+		shape = "octagon"
+	}
+	fmt.Fprintf(w, "\tn%d [label=%s shape=%s];\n", self, strconv.Quote(label), shape)
+
+	for i, c := range n.Children {
+		child := c.writeDOT(w, id)
+		fmt.Fprintf(w, "\tn%d -> n%d [label=%d];\n", self, child, i+1)
+	}
+	return self
+}
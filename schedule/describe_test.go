@@ -0,0 +1,59 @@
+package schedule
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type opaque struct{ Runner }
+
+func TestDescribe(t *testing.T) {
+	stepFunc := func(i int) float64 { return 0 }
+	sched := NewSequence(
+		NewLabeledStep("prior", stepFunc, 0),
+		NewLoop(NewSequence(NewStep(stepFunc, 1), NewStep(stepFunc, 2)), 0.5),
+		opaque{NewStep(stepFunc, 0)},
+	)
+
+	want := Node{Kind: KindSequence, Children: []Node{
+		{Kind: KindStep, Label: "prior"},
+		{Kind: KindLoop, MaxDelta: 0.5, Children: []Node{
+			{Kind: KindSequence, Children: []Node{
+				{Kind: KindStep, Input: 1},
+				{Kind: KindStep, Input: 2},
+			}},
+		}},
+		{Kind: KindRunner, Label: "schedule.opaque"},
+	}}
+
+	got := Describe(sched)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Describe() == %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Node
+	if err = json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, want) {
+		t.Errorf("json round trip == %+v, want %+v", fromJSON, want)
+	}
+
+	var buf bytes.Buffer
+	if err = got.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, s := range []string{"digraph schedule {", `"prior (0)"`, `"loop (Δ ≤ 0.5)"`, "n2 -> n3 [label=1];"} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("WriteDOT() output does not contain %s:\n%s", s, dot)
+		}
+	}
+}
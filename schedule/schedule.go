@@ -2,31 +2,101 @@
 // run in sequences and loops.
 package schedule
 
-import "math"
+import (
+	"math"
+	"runtime"
+	"sync"
+)
 
 // Runner provides an interface for the run function.
 type Runner interface {
 	Run(depth, maxDepth int) float64
 }
 
-// Run runs a schedule starting from zero depth.
-func Run(schedule Runner, maxDepth int) float64 {
-	return schedule.Run(0, maxDepth)
+// Tracer observes a schedule while it is run. The steps of a schedule built
+// by NewParallel are run concurrently, a Tracer observing such a schedule is
+// called from multiple goroutines and must be safe for concurrent use.
+type Tracer interface {
+	// Step is called after a step at depth has run the function for input,
+	// label is the label of the step (empty for steps created by NewStep).
+	Step(depth, input int, label string, delta float64)
+
+	// LoopIteration is called after every iteration of a loop at depth,
+	// iteration counts from one.
+	LoopIteration(depth, iteration int, delta float64)
+}
+
+// Option configures how a schedule is run.
+type Option func(*config)
+
+// Trace sets a Tracer that observes the schedule. Only the schedules provided
+// by this package are traced, a Runner implemented elsewhere is run as is.
+func Trace(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+type config struct {
+	maxDepth int
+	tracer   Tracer
+}
+
+// trace reports if events at depth should be traced.
+func (c *config) trace(depth int) bool {
+	return c.tracer != nil && (c.maxDepth < 0 || depth <= c.maxDepth)
+}
+
+// runner is implemented by the schedules in this package so that the run
+// configuration can be threaded through nested schedules.
+type runner interface {
+	run(depth int, c *config) float64
+}
+
+func runChild(schedule Runner, depth int, c *config) float64 {
+	if r, ok := schedule.(runner); ok {
+		return r.run(depth, c)
+	}
+	return schedule.Run(depth, c.maxDepth)
+}
+
+// Run runs a schedule starting from zero depth. Events deeper than maxDepth
+// are not traced, a negative maxDepth traces events at all depths.
+func Run(schedule Runner, maxDepth int, opts ...Option) float64 {
+	c := config{maxDepth: maxDepth}
+	for _, o := range opts {
+		o(&c)
+	}
+	return runChild(schedule, 0, &c)
 }
 
 type step struct {
 	input    int // Input for function
 	function func(i int) float64
+	label    string
 }
 
 // NewStep returns a step in the schedule with provided function and input.
 func NewStep(function func(i int) float64, input int) Runner {
-	return step{input, function}
+	return step{input: input, function: function}
+}
+
+// NewLabeledStep returns a step like NewStep with a label describing what
+// the step does, the label is shown when the schedule is described or traced.
+func NewLabeledStep(label string, function func(i int) float64, input int) Runner {
+	return step{input: input, function: function, label: label}
 }
 
 // Run runs the step function with the input and returns the delta.
 func (s step) Run(depth, maxDepth int) float64 {
+	return s.run(depth, &config{maxDepth: maxDepth})
+}
+
+func (s step) run(depth int, c *config) float64 {
 	delta := s.function(s.input)
+	if c.trace(depth) {
+		c.tracer.Step(depth, s.input, s.label, delta)
+	}
 	return delta
 }
 
@@ -42,9 +112,59 @@ func NewSequence(sequences ...Runner) Runner {
 // Run runs all runnable schedules in a sequence. The largest delta from any of
 // the runnable schedules is returned.
 func (s sequence) Run(depth, maxDepth int) float64 {
+	return s.run(depth, &config{maxDepth: maxDepth})
+}
+
+func (s sequence) run(depth int, c *config) float64 {
 	var delta float64
 	for _, s := range s.sequences {
-		delta = math.Max(delta, s.Run(depth+1, maxDepth))
+		delta = math.Max(delta, runChild(s, depth+1, c))
+	}
+
+	return delta
+}
+
+type parallel struct {
+	sequences []Runner
+}
+
+// NewParallel returns a new sequence of runnable schedules that are run
+// concurrently, spread over at most GOMAXPROCS goroutines. The schedules must
+// not update the same variables (or messages) so that the result is the same
+// as for NewSequence. A Tracer observing the schedule must be safe for
+// concurrent use.
+func NewParallel(sequences ...Runner) Runner {
+	return parallel{sequences}
+}
+
+// Run runs all runnable schedules concurrently. The largest delta from any of
+// the runnable schedules is returned.
+func (p parallel) Run(depth, maxDepth int) float64 {
+	return p.run(depth, &config{maxDepth: maxDepth})
+}
+
+func (p parallel) run(depth int, c *config) float64 {
+	workers := runtime.GOMAXPROCS(0)
+	if len(p.sequences) < workers {
+		workers = len(p.sequences)
+	}
+
+	deltas := make([]float64, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(p.sequences); i += workers {
+				deltas[w] = math.Max(deltas[w], runChild(p.sequences[i], depth+1, c))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var delta float64
+	for _, d := range deltas {
+		delta = math.Max(delta, d)
 	}
 
 	return delta
@@ -62,9 +182,16 @@ func NewLoop(schedule Runner, maxDelta float64) Runner {
 
 // Run reruns the loop until a desired delta (maxDelta) is reached.
 func (l loop) Run(depth, maxDepth int) float64 {
+	return l.run(depth, &config{maxDepth: maxDepth})
+}
+
+func (l loop) run(depth int, c *config) float64 {
 	delta := math.MaxFloat64
-	for delta > l.maxDelta {
-		delta = l.schedule.Run(depth+1, maxDepth)
+	for i := 1; delta > l.maxDelta; i++ {
+		delta = runChild(l.schedule, depth+1, c)
+		if c.trace(depth) {
+			c.tracer.LoopIteration(depth, i, delta)
+		}
 	}
 
 	return delta
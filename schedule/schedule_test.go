@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -96,3 +97,88 @@ func TestRunSchedule(t *testing.T) {
 		t.Errorf("Run(sequence, -1) iter == %d, want %d", iter, wantIter)
 	}
 }
+
+type traceEvent struct {
+	kind         string
+	depth, input int
+	label        string
+	delta        float64
+}
+
+type recorder struct{ events []traceEvent }
+
+func (r *recorder) Step(depth, input int, label string, delta float64) {
+	r.events = append(r.events, traceEvent{"step", depth, input, label, delta})
+}
+
+func (r *recorder) LoopIteration(depth, iteration int, delta float64) {
+	r.events = append(r.events, traceEvent{"loop", depth, iteration, "", delta})
+}
+
+func TestRunTrace(t *testing.T) {
+	delta := 1.0
+	reduce := func(i int) float64 {
+		delta -= 0.5
+		return delta
+	}
+	sched := NewSequence(
+		NewLabeledStep("first", func(i int) float64 { return float64(i) }, 3),
+		NewLoop(NewSequence(NewLabeledStep("reduce", reduce, 1)), 0),
+	)
+
+	tests := []struct {
+		maxDepth int
+		want     []traceEvent
+	}{
+		{-1, []traceEvent{
+			{"step", 1, 3, "first", 3},
+			{"step", 3, 1, "reduce", 0.5},
+			{"loop", 1, 1, "", 0.5},
+			{"step", 3, 1, "reduce", 0},
+			{"loop", 1, 2, "", 0},
+		}},
+		{1, []traceEvent{
+			{"step", 1, 3, "first", 3},
+			{"loop", 1, 1, "", 0.5},
+			{"loop", 1, 2, "", 0},
+		}},
+		{0, nil},
+	}
+	for _, tt := range tests {
+		delta = 1.0
+		var r recorder
+		got := Run(sched, tt.maxDepth, Trace(&r))
+		if got != 3 {
+			t.Errorf("Run() == %v, want 3", got)
+		}
+		if !reflect.DeepEqual(r.events, tt.want) {
+			t.Errorf("Run(%d) traced %v, want %v", tt.maxDepth, r.events, tt.want)
+		}
+	}
+}
+
+func TestScheduleParallelRun(t *testing.T) {
+	values := make([]float64, 100)
+	var steps []Runner
+	for i := range values {
+		steps = append(steps, NewStep(func(i int) float64 {
+			values[i] = float64(i) / 2
+			return values[i]
+		}, i))
+	}
+
+	result := NewParallel(steps...).Run(0, -1)
+	want := 49.5
+
+	if result != want {
+		t.Errorf("Run(0, -1) == %f, want %f", result, want)
+	}
+	for i, v := range values {
+		if v != float64(i)/2 {
+			t.Errorf("step %d not run", i)
+		}
+	}
+	if result := NewParallel().Run(0, -1); result != 0 {
+		t.Errorf("empty Run(0, -1) == %f, want 0", result)
+	}
+}
@@ -0,0 +1,317 @@
+// Package simulate generates synthetic populations with known true skills
+// and plays simulated matches between them to measure how quickly TrueSkill
+// converges to the truth.
+//
+// Match outcomes follow the TrueSkill performance model: every player
+// performs at its true skill plus gaussian noise with standard deviation
+// beta, players are ranked by performance and two neighbouring players draw
+// when their performances are within the draw margin.
+package simulate
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+// Default simulation configuration.
+const (
+	DefaultSeed       = 1
+	DefaultPopulation = 1000
+	DefaultMatchSize  = 2
+)
+
+// Option represents a simulation option.
+type Option func(s *Simulator)
+
+// Seed sets the seed of the simulation.
+func Seed(seed int64) Option {
+	return func(s *Simulator) {
+		s.seed = seed
+	}
+}
+
+// Population sets the number of players in the simulation.
+func Population(n int) Option {
+	return func(s *Simulator) {
+		s.population = n
+	}
+}
+
+// MatchSize sets the number of players in a free-for-all match, two means
+// head-to-head.
+func MatchSize(n int) Option {
+	return func(s *Simulator) {
+		s.matchSize = n
+	}
+}
+
+// SkillDistribution sets the mean and standard deviation of the true skills.
+// The default is the mu and sigma of the TrueSkill configuration.
+func SkillDistribution(mean, stdDev float64) Option {
+	return func(s *Simulator) {
+		s.skillMean = mean
+		s.skillStdDev = stdDev
+	}
+}
+
+// Tolerance sets the largest difference between estimated and true skill
+// for which a player is considered correctly placed. The default is beta.
+func Tolerance(tolerance float64) Option {
+	return func(s *Simulator) {
+		s.tolerance = tolerance
+	}
+}
+
+// SkillBasedPairing makes the simulator match players with similar
+// estimated skills instead of pairing them at random.
+func SkillBasedPairing() Option {
+	return func(s *Simulator) {
+		s.skillBased = true
+	}
+}
+
+// Player is a simulated player.
+type Player struct {
+	TrueSkill float64          // Ground-truth skill
+	Estimate  trueskill.Player // Estimated skill
+	Games     int              // Number of matches played
+
+	history []trueskill.Player // Estimate after every match
+}
+
+// Simulator plays rounds of simulated matches. Given the same seed and
+// options, a Simulator always produces the same results.
+type Simulator struct {
+	ts          trueskill.Config
+	seed        int64
+	population  int
+	matchSize   int
+	skillMean   float64
+	skillStdDev float64
+	tolerance   float64
+	skillBased  bool
+
+	rnd     *rand.Rand
+	players []Player
+	rounds  int
+}
+
+// New returns a simulator with a fresh population where every player starts
+// from the prior of the TrueSkill configuration.
+func New(ts trueskill.Config, opts ...Option) *Simulator {
+	s := &Simulator{
+		ts:          ts,
+		seed:        DefaultSeed,
+		population:  DefaultPopulation,
+		matchSize:   DefaultMatchSize,
+		skillMean:   ts.Mu(),
+		skillStdDev: ts.Sigma(),
+		tolerance:   ts.Beta(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+
+	s.rnd = rand.New(rand.NewSource(s.seed))
+	s.players = make([]Player, s.population)
+	for i := range s.players {
+		s.players[i] = Player{
+			TrueSkill: s.skillMean + s.rnd.NormFloat64()*s.skillStdDev,
+			Estimate:  ts.NewPlayer(),
+		}
+	}
+
+	return s
+}
+
+// Players returns the simulated players.
+func (s *Simulator) Players() []Player {
+	return s.players
+}
+
+// Outcome simulates a match between the players with the provided indexes.
+// It returns the indexes ordered by rank and the draws between neighbouring
+// players, as expected by trueskill.Config.AdjustSkillsWithDraws.
+func (s *Simulator) Outcome(players []int) (ranking []int, draws []bool) {
+	beta := s.ts.Beta()
+	performance := make(map[int]float64, len(players))
+	for _, i := range players {
+		performance[i] = s.players[i].TrueSkill + s.rnd.NormFloat64()*beta
+	}
+
+	ranking = append([]int(nil), players...)
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return performance[ranking[i]] > performance[ranking[j]]
+	})
+
+	margin := s.ts.DrawMargin(2)
+	for i := 0; i < len(ranking)-1; i++ {
+		draws = append(draws, performance[ranking[i]]-performance[ranking[i+1]] <= margin)
+	}
+
+	return ranking, draws
+}
+
+// Play simulates a match between the players with the provided indexes and
+// updates their estimated skills.
+func (s *Simulator) Play(players []int) {
+	ranking, draws := s.Outcome(players)
+
+	skills := make([]trueskill.Player, len(ranking))
+	for k, i := range ranking {
+		skills[k] = s.players[i].Estimate
+	}
+	newSkills, _ := s.ts.AdjustSkillsWithDraws(skills, draws)
+
+	for k, i := range ranking {
+		p := &s.players[i]
+		p.Estimate = newSkills[k]
+		p.Games++
+		p.history = append(p.history, p.Estimate)
+	}
+}
+
+// Round lets every player play one match, players left over when the
+// population is not divisible by the match size sit out.
+func (s *Simulator) Round() {
+	order := s.rnd.Perm(len(s.players))
+	if s.skillBased {
+		sort.SliceStable(order, func(i, j int) bool {
+			return s.players[order[i]].Estimate.Mu() > s.players[order[j]].Estimate.Mu()
+		})
+	}
+
+	for k := 0; k+s.matchSize <= len(order); k += s.matchSize {
+		s.Play(order[k : k+s.matchSize])
+	}
+	s.rounds++
+}
+
+// Run plays the provided number of rounds and returns a report covering
+// every round played so far.
+func (s *Simulator) Run(rounds int) Report {
+	for r := 0; r < rounds; r++ {
+		s.Round()
+	}
+	return s.Report()
+}
+
+// GameStats describes the population after a number of games.
+type GameStats struct {
+	Games        int     // Number of games played
+	Players      int     // Players that have played this many games
+	MeanAbsError float64 // Mean of |mu - true skill|
+	RMSE         float64 // Root mean squared error of mu
+	MeanSigma    float64 // Mean of the estimated sigma
+	Placed       float64 // Fraction of players within tolerance
+}
+
+// Report describes the convergence of the estimated skills.
+type Report struct {
+	// Games holds statistics after every number of games played,
+	// Games[k] describes the players after k+1 games.
+	Games []GameStats
+
+	// Placement holds, per player, the number of games after which the
+	// estimate stayed within tolerance of the true skill for the rest of
+	// the simulation. Minus one means the player was never placed.
+	Placement []int
+
+	// MedianPlacement is the median of Placement, counting players that
+	// were never placed as placed one game after all their games.
+	MedianPlacement float64
+
+	// Final holds the statistics of the current estimates.
+	Final GameStats
+}
+
+// Report returns a report covering every round played so far.
+func (s *Simulator) Report() Report {
+	var r Report
+
+	maxGames := 0
+	for _, p := range s.players {
+		if p.Games > maxGames {
+			maxGames = p.Games
+		}
+	}
+
+	for k := 0; k < maxGames; k++ {
+		st := GameStats{Games: k + 1}
+		for _, p := range s.players {
+			if len(p.history) > k {
+				s.addStats(&st, p.history[k], p.TrueSkill)
+			}
+		}
+		finishStats(&st)
+		r.Games = append(r.Games, st)
+	}
+
+	var placements []float64
+	for _, p := range s.players {
+		placed := len(p.history)
+		for placed > 0 && s.placed(p.history[placed-1], p.TrueSkill) {
+			placed--
+		}
+		if placed == len(p.history) {
+			r.Placement = append(r.Placement, -1)
+			placements = append(placements, float64(len(p.history)+1))
+			continue
+		}
+		// history[placed] is the estimate after placed+1 games.
+		r.Placement = append(r.Placement, placed+1)
+		placements = append(placements, float64(placed+1))
+	}
+	r.MedianPlacement = median(placements)
+
+	final := GameStats{Games: s.rounds}
+	for _, p := range s.players {
+		s.addStats(&final, p.Estimate, p.TrueSkill)
+	}
+	finishStats(&final)
+	r.Final = final
+
+	return r
+}
+
+func (s *Simulator) placed(estimate trueskill.Player, trueSkill float64) bool {
+	return math.Abs(estimate.Mu()-trueSkill) <= s.tolerance
+}
+
+func (s *Simulator) addStats(st *GameStats, estimate trueskill.Player, trueSkill float64) {
+	e := math.Abs(estimate.Mu() - trueSkill)
+	st.Players++
+	st.MeanAbsError += e
+	st.RMSE += e * e
+	st.MeanSigma += estimate.Sigma()
+	if s.placed(estimate, trueSkill) {
+		st.Placed++
+	}
+}
+
+func finishStats(st *GameStats) {
+	if st.Players == 0 {
+		return
+	}
+	n := float64(st.Players)
+	st.MeanAbsError /= n
+	st.RMSE = math.Sqrt(st.RMSE / n)
+	st.MeanSigma /= n
+	st.Placed /= n
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,106 @@
+package simulate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mafredri/go-trueskill"
+)
+
+func TestSimulatorConverges(t *testing.T) {
+	ts := trueskill.New()
+	s := New(ts, Population(200), Seed(3))
+	r := s.Run(30)
+
+	if len(r.Games) != 30 {
+		t.Fatalf("len(Games) == %d, want 30", len(r.Games))
+	}
+	first, last := r.Games[0], r.Games[len(r.Games)-1]
+	if last.MeanAbsError >= first.MeanAbsError {
+		t.Errorf("MeanAbsError did not shrink: %.3f after 1 game, %.3f after 30", first.MeanAbsError, last.MeanAbsError)
+	}
+	if last.MeanSigma >= first.MeanSigma {
+		t.Errorf("MeanSigma did not shrink: %.3f after 1 game, %.3f after 30", first.MeanSigma, last.MeanSigma)
+	}
+	if last.Placed <= first.Placed {
+		t.Errorf("Placed did not grow: %.3f after 1 game, %.3f after 30", first.Placed, last.Placed)
+	}
+	if r.Final != last {
+		t.Errorf("Final == %+v, want %+v", r.Final, last)
+	}
+	if len(r.Placement) != 200 {
+		t.Errorf("len(Placement) == %d, want 200", len(r.Placement))
+	}
+	if r.MedianPlacement <= 0 || r.MedianPlacement > 30 {
+		t.Errorf("MedianPlacement == %v, want between 0 and 30", r.MedianPlacement)
+	}
+}
+
+func TestSimulatorDeterministic(t *testing.T) {
+	ts := trueskill.New()
+	a := New(ts, Population(50), MatchSize(4), SkillBasedPairing(), Seed(9)).Run(10)
+	b := New(ts, Population(50), MatchSize(4), SkillBasedPairing(), Seed(9)).Run(10)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("reports differ between runs with the same seed")
+	}
+}
+
+func TestSimulatorOutcome(t *testing.T) {
+	ts := trueskill.New(trueskill.DrawProbabilityZero())
+	s := New(ts, Population(2), SkillDistribution(25, 0))
+	s.players[0].TrueSkill = 1000
+
+	for i := 0; i < 10; i++ {
+		ranking, draws := s.Outcome([]int{1, 0})
+		if ranking[0] != 0 || draws[0] {
+			t.Fatalf("Outcome() == %v, %v, want [0 1] [false]", ranking, draws)
+		}
+	}
+}
+
+func TestSimulatorNeverPlaced(t *testing.T) {
+	ts := trueskill.New()
+	r := New(ts, Population(20), Tolerance(0), Seed(5)).Run(8)
+
+	for i, p := range r.Placement {
+		if p != -1 {
+			t.Errorf("Placement[%d] == %d, want -1", i, p)
+		}
+	}
+	// Players that were never placed count as placed one game after all
+	// their games.
+	if r.MedianPlacement != 9 {
+		t.Errorf("MedianPlacement == %v, want 9", r.MedianPlacement)
+	}
+}
+
+func TestSimulatorPlacement(t *testing.T) {
+	ts := trueskill.New()
+	s := New(ts, Population(4), Tolerance(1))
+
+	for i, mus := range [][]float64{
+		{25},             // Placed after the first game
+		{30, 25.5, 24.5}, // Placed after the second game
+		{30, 30, 25},     // Placed after the last game
+		{30, 30, 30},     // Never placed
+	} {
+		p := &s.players[i]
+		p.TrueSkill = 25
+		p.history = nil
+		for _, mu := range mus {
+			p.history = append(p.history, trueskill.NewPlayer(mu, 1))
+		}
+		p.Estimate = p.history[len(p.history)-1]
+		p.Games = len(p.history)
+	}
+
+	r := s.Report()
+	if want := []int{1, 2, 3, -1}; !reflect.DeepEqual(r.Placement, want) {
+		t.Errorf("Placement == %v, want %v", r.Placement, want)
+	}
+	// The median of 1, 2, 3 and 4, one game after the never placed
+	// player's last.
+	if r.MedianPlacement != 2.5 {
+		t.Errorf("MedianPlacement == %v, want 2.5", r.MedianPlacement)
+	}
+}
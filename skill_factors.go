@@ -1,6 +1,8 @@
 package trueskill
 
 import (
+	"strconv"
+
 	"github.com/mafredri/go-trueskill/collection"
 	"github.com/mafredri/go-trueskill/factor"
 	"github.com/mafredri/go-trueskill/schedule"
@@ -13,6 +15,18 @@ type skillFactors struct {
 	playerPerformanceDifferences             []int
 	performanceToPerformanceDifferencFactors []factor.Factor
 	greatherThanOrWithinFactors              []factor.Factor
+	variableNames                            []string // Names of the variables in the variable bag
+}
+
+// newVariable returns the index of a new variable in varBag and records its
+// name as prefix followed by i.
+func (sf *skillFactors) newVariable(varBag *collection.DistributionBag, prefix string, i int) int {
+	idx := varBag.NextIndex()
+	for len(sf.variableNames) <= idx {
+		sf.variableNames = append(sf.variableNames, "")
+	}
+	sf.variableNames[idx] = prefix + strconv.Itoa(i)
+	return idx
 }
 
 func buildSkillFactors(ts Config, players []Player, draws []bool, varBag *collection.DistributionBag) (skillFactors, []int, factor.List) {
@@ -24,33 +38,36 @@ func buildSkillFactors(ts Config, players []Player, draws []bool, varBag *collec
 
 	var skillIndex []int
 	for i := 0; i < numPlayers; i++ {
-		skillIndex = append(skillIndex, varBag.NextIndex())
+		skillIndex = append(skillIndex, sf.newVariable(varBag, "skill", i))
 	}
 
 	for i := 0; i < numPlayers; i++ {
 		priorSkill := players[i]
 		gpf := gf.GaussianPrior(priorSkill.Mean(), priorSkill.Variance()+(ts.tau*ts.tau), skillIndex[i], varBag)
+		gpf.Name = "prior" + strconv.Itoa(i)
 		sf.skillPriorFactors = append(sf.skillPriorFactors, gpf)
 		factorList.Add(gpf)
 	}
 
 	for i := 0; i < numPlayers; i++ {
-		sf.playerPerformances = append(sf.playerPerformances, varBag.NextIndex())
+		sf.playerPerformances = append(sf.playerPerformances, sf.newVariable(varBag, "perf", i))
 	}
 
 	for i := 0; i < numPlayers; i++ {
 		glf := gf.GaussianLikeliehood(ts.beta*ts.beta, sf.playerPerformances[i], skillIndex[i], varBag, varBag)
+		glf.Name = "likelihood" + strconv.Itoa(i)
 		sf.skillToPerformanceFactors = append(sf.skillToPerformanceFactors, glf)
 		factorList.Add(glf)
 	}
 
 	for i := 0; i < numPlayers-1; i++ {
-		sf.playerPerformanceDifferences = append(sf.playerPerformanceDifferences, varBag.NextIndex())
+		sf.playerPerformanceDifferences = append(sf.playerPerformanceDifferences, sf.newVariable(varBag, "diff", i))
 	}
 
 	for i := 0; i < numPlayers-1; i++ {
 		gws := gf.GaussianWeightedSum(1.0, -1.0, sf.playerPerformanceDifferences[i], sf.playerPerformances[i],
 			sf.playerPerformances[i+1], varBag, varBag, varBag)
+		gws.Name = "sum" + strconv.Itoa(i)
 		sf.performanceToPerformanceDifferencFactors = append(sf.performanceToPerformanceDifferencFactors, gws)
 		factorList.Add(gws)
 	}
@@ -63,8 +80,10 @@ func buildSkillFactors(ts Config, players []Player, draws []bool, varBag *collec
 		var f factor.Factor
 		if draw {
 			f = gf.GaussianWithin(epsilon, sf.playerPerformanceDifferences[i], varBag)
+			f.Name = "within" + strconv.Itoa(i)
 		} else {
 			f = gf.GaussianGreaterThan(epsilon, sf.playerPerformanceDifferences[i], varBag)
+			f.Name = "greaterThan" + strconv.Itoa(i)
 		}
 		sf.greatherThanOrWithinFactors = append(sf.greatherThanOrWithinFactors, f)
 		factorList.Add(f)
@@ -73,14 +92,21 @@ func buildSkillFactors(ts Config, players []Player, draws []bool, varBag *collec
 	return sf, skillIndex, factorList
 }
 
-func skillFactorListToScheduleStep(facs []factor.Factor, idx int) []schedule.Runner {
+// skillFactorListToSchedule returns a schedule updating message idx of every
+// factor, the factors must not share variables.
+func skillFactorListToSchedule(facs []factor.Factor, idx int) schedule.Runner {
 	var steps []schedule.Runner
 	for _, f := range facs {
-		step := schedule.NewStep(f.UpdateMessage, idx)
-		steps = append(steps, step)
+		steps = append(steps, factorStep(f, idx))
 	}
 
-	return steps
+	return schedule.NewSequence(steps...)
+}
+
+// factorStep returns a schedule step updating message idx of f, labeled with
+// the name of the factor.
+func factorStep(f factor.Factor, idx int) schedule.Runner {
+	return schedule.NewLabeledStep(f.Name, f.UpdateMessage, idx)
 }
 
 // buildSkillFactorSchedule builds a full schedule that represents all the steps
@@ -89,8 +115,8 @@ func buildSkillFactorSchedule(numPlayers int, sf skillFactors, loopMaxDelta floa
 	// Prior schedule initializes the skill priors for all players and updates
 	// the performance
 	priorSchedule := schedule.NewSequence(
-		schedule.NewSequence(skillFactorListToScheduleStep(sf.skillPriorFactors, 0)...),
-		schedule.NewSequence(skillFactorListToScheduleStep(sf.skillToPerformanceFactors, 0)...),
+		skillFactorListToSchedule(sf.skillPriorFactors, 0),
+		skillFactorListToSchedule(sf.skillToPerformanceFactors, 0),
 	)
 
 	// Loop schedule iterates until desired accuracy is reached
@@ -100,8 +126,8 @@ func buildSkillFactorSchedule(numPlayers int, sf skillFactors, loopMaxDelta floa
 		// In two player mode there is no loop, just send the performance
 		// difference and the greater-than.
 		loopSchedule = schedule.NewSequence(
-			schedule.NewStep(sf.performanceToPerformanceDifferencFactors[0].UpdateMessage, 0),
-			schedule.NewStep(sf.greatherThanOrWithinFactors[0].UpdateMessage, 0),
+			factorStep(sf.performanceToPerformanceDifferencFactors[0], 0),
+			factorStep(sf.greatherThanOrWithinFactors[0], 0),
 		)
 	} else {
 		// Forward schedule updates the factor graph in one direction
@@ -111,16 +137,16 @@ func buildSkillFactorSchedule(numPlayers int, sf skillFactors, loopMaxDelta floa
 
 		for i := 0; i < numPlayers-2; i++ {
 			forwardSteps := []schedule.Runner{
-				schedule.NewStep(sf.performanceToPerformanceDifferencFactors[i].UpdateMessage, 0),
-				schedule.NewStep(sf.greatherThanOrWithinFactors[i].UpdateMessage, 0),
-				schedule.NewStep(sf.performanceToPerformanceDifferencFactors[i].UpdateMessage, 2),
+				factorStep(sf.performanceToPerformanceDifferencFactors[i], 0),
+				factorStep(sf.greatherThanOrWithinFactors[i], 0),
+				factorStep(sf.performanceToPerformanceDifferencFactors[i], 2),
 			}
 			forwardSchedule = append(forwardSchedule, forwardSteps...)
 
 			backwardSteps := []schedule.Runner{
-				schedule.NewStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2-i].UpdateMessage, 0),
-				schedule.NewStep(sf.greatherThanOrWithinFactors[numPlayers-2-i].UpdateMessage, 0),
-				schedule.NewStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2-i].UpdateMessage, 1),
+				factorStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2-i], 0),
+				factorStep(sf.greatherThanOrWithinFactors[numPlayers-2-i], 0),
+				factorStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2-i], 1),
 			}
 			backwardSchedule = append(backwardSchedule, backwardSteps...)
 		}
@@ -139,12 +165,12 @@ func buildSkillFactorSchedule(numPlayers int, sf skillFactors, loopMaxDelta floa
 
 	innerSchedule := schedule.NewSequence(
 		loopSchedule,
-		schedule.NewStep(sf.performanceToPerformanceDifferencFactors[0].UpdateMessage, 1),
-		schedule.NewStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2].UpdateMessage, 2),
+		factorStep(sf.performanceToPerformanceDifferencFactors[0], 1),
+		factorStep(sf.performanceToPerformanceDifferencFactors[numPlayers-2], 2),
 	)
 
 	// Finally send the skill performances of all players
-	posteriorSchedule := schedule.NewSequence(skillFactorListToScheduleStep(sf.skillToPerformanceFactors, 1)...)
+	posteriorSchedule := skillFactorListToSchedule(sf.skillToPerformanceFactors, 1)
 
 	// Combine all schedules into one runnable sequence
 	fullSchedule := schedule.NewSequence(priorSchedule, innerSchedule, posteriorSchedule)
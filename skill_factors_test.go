@@ -0,0 +1,64 @@
+package trueskill
+
+import (
+	"testing"
+
+	"github.com/mafredri/go-trueskill/collection"
+	"github.com/mafredri/go-trueskill/factor"
+	"github.com/mafredri/go-trueskill/gaussian"
+	"github.com/mafredri/go-trueskill/mathextra"
+	"github.com/mafredri/go-trueskill/schedule"
+)
+
+func TestDerivedSkillFactorSchedule(t *testing.T) {
+	ts := New()
+	for _, draws := range [][]bool{
+		{false},
+		{true},
+		{false, true, false, false},
+	} {
+		var players []Player
+		for i := 0; i <= len(draws); i++ {
+			players = append(players, NewPlayer(25+float64(i), 8-float64(i)))
+		}
+		want, err := ts.Rate(players, draws)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		varBag := collection.NewDistributionBag(gaussian.NewFromPrecision(0, 0))
+		_, skillIndex, factorList := buildSkillFactors(ts, players, draws, varBag)
+		sched := factor.Schedule(factorList.Factors(), loopMaxDelta)
+		schedule.Run(sched, -1)
+
+		for i, id := range skillIndex {
+			got := varBag.Get(id)
+			if !mathextra.Float64AlmostEq(got.Mean(), want.Players[i].Mu(), 1e-4) ||
+				!mathextra.Float64AlmostEq(got.StdDev(), want.Players[i].Sigma(), 1e-4) {
+				t.Errorf("%v: player %d == %v, want %v", draws, i, Player{got}, want.Players[i])
+			}
+		}
+		if got := factorList.LogNormalization(); !mathextra.Float64AlmostEq(got, want.LogEvidence, 1e-4) {
+			t.Errorf("%v: LogNormalization() == %v, want %v", draws, got, want.LogEvidence)
+		}
+
+		wantLoops := 0
+		if len(players) > 2 {
+			wantLoops = 1
+		}
+		if loops := countKind(schedule.Describe(sched), schedule.KindLoop); loops != wantLoops {
+			t.Errorf("%v: derived schedule has %d loops, want %d", draws, loops, wantLoops)
+		}
+	}
+}
+
+func countKind(n schedule.Node, kind string) int {
+	var count int
+	if n.Kind == kind {
+		count++
+	}
+	for _, c := range n.Children {
+		count += countKind(c, kind)
+	}
+	return count
+}
@@ -0,0 +1,130 @@
+package trueskill
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var errComponentOutOfRange = errors.New("skill component out of range")
+
+// SkillVector is the skill of a player that depends on what is played, e.g.
+// the role, map or character. The skill of the player in a match is the
+// general skill, shared by all components, plus the skill of the component
+// played. Both are updated from the outcome, so a result in an unfamiliar
+// component moves the general skill less than the component skill when the
+// component is uncertain.
+type SkillVector struct {
+	General    Player   // General skill shared by every component
+	Components []Player // Skill of every component relative to General
+}
+
+// NewSkillVector returns a skill vector with the provided general skill and
+// n components. Every component starts at mean zero with standard deviation
+// sigma, how far the skill in a single component is expected to deviate from
+// the general skill.
+func NewSkillVector(general Player, n int, sigma float64) SkillVector {
+	v := SkillVector{General: general}
+	for i := 0; i < n; i++ {
+		v.Components = append(v.Components, NewPlayer(0, sigma))
+	}
+	return v
+}
+
+// Skill returns the skill of the player in component c, the sum of the
+// general skill and the skill of the component.
+func (v SkillVector) Skill(c int) Player {
+	return Player{Gaussian: v.General.Sum(v.Components[c].Gaussian)}
+}
+
+func (v SkillVector) String() string {
+	return fmt.Sprintf("SkillVector(general=%v components=%v)", v.General, v.Components)
+}
+
+// SkillVectorResult is the outcome of rating a match between players with
+// skill vectors.
+type SkillVectorResult struct {
+	// Players holds the new skill vector of every player. Only the general
+	// skill and the component played are changed.
+	Players []SkillVector
+
+	// Advantage is the new advantage of the side set by the SideAdvantage
+	// option, the zero value if the option is not used.
+	Advantage Advantage
+
+	// LogEvidence is the natural logarithm of the probability of the
+	// outcome given the skills before the match.
+	LogEvidence float64
+}
+
+// Probability returns the probability of the outcome given the skills
+// before the match.
+func (r SkillVectorResult) Probability() float64 {
+	return math.Exp(r.LogEvidence)
+}
+
+// RateSkillVectors rates a match like Rate between players with skill
+// vectors, components[i] is the component played by players[i]. The
+// dynamics factor tau is added once per player, to the general skill. The
+// skill vectors of anchored players are returned unchanged.
+func (ts Config) RateSkillVectors(players []SkillVector, components []int, draws []bool, opts ...MatchOption) (SkillVectorResult, error) {
+	if len(players) < 2 {
+		return SkillVectorResult{}, errTooFewPlayers
+	}
+	if len(draws) != len(players)-1 {
+		return SkillVectorResult{}, fmt.Errorf("draws slice should have length %d but have %d instead", len(players)-1, len(draws))
+	}
+	if len(components) != len(players) {
+		return SkillVectorResult{}, fmt.Errorf("components slice should have length %d but have %d instead", len(players), len(components))
+	}
+	mc, err := newMatchConfig(len(players), opts)
+	if err != nil {
+		return SkillVectorResult{}, err
+	}
+
+	mps := make([]matchPlayer, len(players))
+	for i, p := range players {
+		c := components[i]
+		if c < 0 || c >= len(p.Components) {
+			return SkillVectorResult{}, fmt.Errorf("player %d: %v", i, errComponentOutOfRange)
+		}
+		mps[i] = mc.matchPlayer(i, p.General, p.Components[c])
+		for _, s := range mps[i].skills {
+			if err := s.Check(); err != nil {
+				return SkillVectorResult{}, fmt.Errorf("player %d: %v", i, err)
+			}
+		}
+	}
+
+	m := ts.buildMatchGraph(mps, draws, mc.advantage)
+	m.run()
+	if err := m.graph.Err(); err != nil {
+		return SkillVectorResult{}, err
+	}
+
+	var r SkillVectorResult
+	for i, p := range players {
+		if mps[i].anchored {
+			r.Players = append(r.Players, p)
+			continue
+		}
+		v := SkillVector{
+			General:    m.skill(i, 0),
+			Components: append([]Player(nil), p.Components...),
+		}
+		v.Components[components[i]] = m.skill(i, 1)
+		if !v.General.IsProper() || !v.Components[components[i]].IsProper() {
+			return SkillVectorResult{}, errRatingNotFinite
+		}
+		r.Players = append(r.Players, v)
+	}
+	if m.advantage != -1 {
+		r.Advantage = Advantage{Gaussian: m.graph.Marginal(m.advantage)}
+	}
+	r.LogEvidence = m.graph.LogNormalization()
+	if math.IsNaN(r.LogEvidence) {
+		return SkillVectorResult{}, errRatingNotFinite
+	}
+
+	return r, nil
+}
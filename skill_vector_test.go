@@ -0,0 +1,133 @@
+package trueskill
+
+import (
+	"testing"
+
+	"github.com/mafredri/go-trueskill/mathextra"
+)
+
+func TestRateSkillVectors(t *testing.T) {
+	ts := New(Tau(0))
+	players := []SkillVector{
+		NewSkillVector(NewPlayer(25, 6), 3, 4),
+		NewSkillVector(NewPlayer(30, 3), 3, 2),
+		NewSkillVector(NewPlayer(20, 5), 3, 3),
+	}
+	components := []int{1, 0, 2}
+	draws := []bool{false, true}
+
+	r, err := ts.RateSkillVectors(players, components, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The performance only depends on the combined skill, rating the
+	// combined skills gives the same change in mean.
+	var combined []Player
+	for i, p := range players {
+		combined = append(combined, p.Skill(components[i]))
+	}
+	want, err := ts.Rate(combined, draws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mathextra.Float64AlmostEq(r.LogEvidence, want.LogEvidence, 1e-4) {
+		t.Errorf("LogEvidence == %v, want %v", r.LogEvidence, want.LogEvidence)
+	}
+
+	for i, p := range r.Players {
+		c := components[i]
+		general := p.General.Mu() - players[i].General.Mu()
+		component := p.Components[c].Mu() - players[i].Components[c].Mu()
+		if !mathextra.Float64AlmostEq(general+component, want.Changes[i].MuDelta, 1e-4) {
+			t.Errorf("player %d: mean change == %v, want %v", i, general+component, want.Changes[i].MuDelta)
+		}
+
+		// The change is split in proportion to the variances.
+		ratio := players[i].General.Variance() / players[i].Components[c].Variance()
+		if !mathextra.Float64AlmostEq(general/component, ratio, 1e-6) {
+			t.Errorf("player %d: general/component change == %v, want %v", i, general/component, ratio)
+		}
+
+		for j, comp := range p.Components {
+			if j != c && comp != players[i].Components[j] {
+				t.Errorf("player %d: component %d == %v, want unchanged %v", i, j, comp, players[i].Components[j])
+			}
+		}
+	}
+}
+
+func TestRateSkillVectors_OneComponent(t *testing.T) {
+	ts := New()
+	players := []SkillVector{
+		NewSkillVector(NewPlayer(25, 6), 1, 1e-4),
+		NewSkillVector(NewPlayer(30, 3), 1, 1e-4),
+		NewSkillVector(NewPlayer(20, 5), 1, 1e-4),
+	}
+	components := []int{0, 0, 0}
+	draws := []bool{false, true}
+
+	var skills []Player
+	for _, p := range players {
+		skills = append(skills, p.Skill(0))
+	}
+
+	// With a component that is close to exactly known the skill vector is
+	// rated like its combined skill, tau is only added once.
+	tests := []struct {
+		name string
+		opts []MatchOption
+	}{
+		{"None", nil},
+		{"SideAdvantage", []MatchOption{SideAdvantage(1, NewAdvantage(1, 2))}},
+		{"Handicap", []MatchOption{Handicap(2, 3)}},
+		{"Anchor", []MatchOption{Anchor(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ts.RateSkillVectors(players, components, draws, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ts.Rate(skills, draws, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, p := range r.Players {
+				got := p.Skill(0)
+				if !mathextra.Float64AlmostEq(got.Mu(), want.Players[i].Mu(), 1e-6) ||
+					!mathextra.Float64AlmostEq(got.Sigma(), want.Players[i].Sigma(), 1e-6) {
+					t.Errorf("player %d: Skill(0) == %v, want %v", i, got, want.Players[i])
+				}
+			}
+			if !mathextra.Float64AlmostEq(r.Advantage.PrecisionMean, want.Advantage.PrecisionMean, 1e-6) ||
+				!mathextra.Float64AlmostEq(r.Advantage.Precision, want.Advantage.Precision, 1e-6) {
+				t.Errorf("Advantage == %v, want %v", r.Advantage, want.Advantage)
+			}
+			if !mathextra.Float64AlmostEq(r.LogEvidence, want.LogEvidence, 1e-6) {
+				t.Errorf("LogEvidence == %v, want %v", r.LogEvidence, want.LogEvidence)
+			}
+		})
+	}
+}
+
+func TestRateSkillVectors_Errors(t *testing.T) {
+	ts := New()
+	players := []SkillVector{
+		NewSkillVector(ts.NewPlayer(), 2, 4),
+		NewSkillVector(ts.NewPlayer(), 2, 4),
+	}
+	if _, err := ts.RateSkillVectors(players, []int{0, 2}, []bool{false}); err == nil {
+		t.Error("RateSkillVectors() with an out of range component did not return an error")
+	}
+	if _, err := ts.RateSkillVectors(players, []int{0}, []bool{false}); err == nil {
+		t.Error("RateSkillVectors() with missing components did not return an error")
+	}
+	if _, err := ts.RateSkillVectors(players[:1], []int{0}, nil); err == nil {
+		t.Error("RateSkillVectors() with one player did not return an error")
+	}
+	if _, err := ts.RateSkillVectors(players, []int{0, 1}, []bool{false}, SideAdvantage(2, NewAdvantage(0, 1))); err == nil {
+		t.Error("RateSkillVectors() with an out of range advantage side did not return an error")
+	}
+}
@@ -4,30 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"math"
-
-	"github.com/mafredri/go-trueskill/collection"
-	"github.com/mafredri/go-trueskill/gaussian"
-	"github.com/mafredri/go-trueskill/schedule"
+	"time"
 )
 
 // Constants for the TrueSkill ranking system.
 const (
-	DefaultMu              = 25.0
-	DefaultSigma           = DefaultMu / 3.0
-	DefaultBeta            = DefaultSigma * 0.5
-	DefaultTau             = DefaultSigma * 0.01
-	DefaultDrawProbability = 10.0 // Percentage, between 0 and 100.
+	DefaultMu               = 25.0
+	DefaultSigma            = DefaultMu / 3.0
+	DefaultBeta             = DefaultSigma * 0.5
+	DefaultTau              = DefaultSigma * 0.01
+	DefaultDrawProbability  = 10.0 // Percentage, between 0 and 100.
+	DefaultProvisionalGames = 10   // Games before a rating is no longer provisional.
 
 	loopMaxDelta = 1e-4 // Desired accuracy for factor graph loop schedule
 )
 
 // Config is the configuration for the TrueSkill ranking system
 type Config struct {
-	mu              float64 // Mean
-	sigma           float64 // Standard deviation
-	beta            float64 // Skill class width (length of skill chain)
-	tau             float64 // Additive dynamics factor
-	drawProbability float64 // Probability of a draw, between zero and a one
+	mu               float64 // Mean
+	sigma            float64 // Standard deviation
+	beta             float64 // Skill class width (length of skill chain)
+	tau              float64 // Additive dynamics factor
+	drawProbability  float64 // Probability of a draw, between zero and a one
+	provisionalGames int     // Number of games a rating is considered provisional
 }
 
 func (ts Config) String() string {
@@ -36,8 +35,70 @@ func (ts Config) String() string {
 
 var (
 	errDrawProbabilityOutOfRange = errors.New("draw probability must be between 0 and 100")
+	errMuNotFinite               = errors.New("mu must be a finite number")
+	errSigmaNotPositive          = errors.New("sigma must be a positive finite number")
+	errBetaNotPositive           = errors.New("beta must be a positive finite number")
+	errTauNegative               = errors.New("tau must be a non-negative finite number")
+	errProvisionalGamesNegative  = errors.New("provisional games must not be negative")
 )
 
+// Mu returns the mean of a new player.
+func (ts Config) Mu() float64 {
+	return ts.mu
+}
+
+// Sigma returns the standard deviation of a new player.
+func (ts Config) Sigma() float64 {
+	return ts.sigma
+}
+
+// Beta returns the skill class width (length of skill chain).
+func (ts Config) Beta() float64 {
+	return ts.beta
+}
+
+// Tau returns the additive dynamics factor.
+func (ts Config) Tau() float64 {
+	return ts.tau
+}
+
+// DrawProbability returns the probability of a draw as a value between 0 and
+// 100, the same range accepted by the DrawProbability option.
+func (ts Config) DrawProbability() float64 {
+	return ts.drawProbability * 100
+}
+
+// ProvisionalGames returns the number of rated games an IdentifiedPlayer must
+// play before its rating is no longer provisional.
+func (ts Config) ProvisionalGames() int {
+	return ts.provisionalGames
+}
+
+// Validate checks that the configuration can be used to rate players. Sigma
+// and beta must be positive, tau must not be negative and no value may be
+// NaN or infinite.
+func (ts Config) Validate() error {
+	switch {
+	case !isFinite(ts.mu):
+		return errMuNotFinite
+	case !isFinite(ts.sigma) || ts.sigma <= 0:
+		return errSigmaNotPositive
+	case !isFinite(ts.beta) || ts.beta <= 0:
+		return errBetaNotPositive
+	case !isFinite(ts.tau) || ts.tau < 0:
+		return errTauNegative
+	case !(ts.drawProbability >= 0 && ts.drawProbability <= 1):
+		return errDrawProbabilityOutOfRange
+	case ts.provisionalGames < 0:
+		return errProvisionalGamesNegative
+	}
+	return nil
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
 // Option represents a configuration option.
 type Option func(c *Config)
 
@@ -73,7 +134,7 @@ func Tau(tau float64) Option {
 // sets the probability of a draw. An error is returned if the input value is
 // out of range.
 func DrawProbability(prob float64) (Option, error) {
-	if prob < 0.0 || prob > 100.0 {
+	if !(prob >= 0.0 && prob <= 100.0) {
 		return nil, errDrawProbabilityOutOfRange
 	}
 	return func(c *Config) {
@@ -90,15 +151,24 @@ func DrawProbabilityZero() Option {
 	}
 }
 
+// ProvisionalGames sets the number of rated games an IdentifiedPlayer must
+// play before its rating is no longer considered provisional.
+func ProvisionalGames(n int) Option {
+	return func(c *Config) {
+		c.provisionalGames = n
+	}
+}
+
 // New creates a new TrueSkill configuration with default configuration.
 // The configuration can be changed by providing one or multiple Option.
 func New(opts ...Option) Config {
 	c := Config{
-		mu:              DefaultMu,
-		sigma:           DefaultSigma,
-		beta:            DefaultBeta,
-		tau:             DefaultTau,
-		drawProbability: DefaultDrawProbability,
+		mu:               DefaultMu,
+		sigma:            DefaultSigma,
+		beta:             DefaultBeta,
+		tau:              DefaultTau,
+		drawProbability:  DefaultDrawProbability,
+		provisionalGames: DefaultProvisionalGames,
 	}
 	for _, o := range opts {
 		o(&c)
@@ -110,10 +180,24 @@ func New(opts ...Option) Config {
 	return c
 }
 
+// NewChecked works like New but validates the resulting configuration, an
+// error is returned if any of the options is out of range.
+func NewChecked(opts ...Option) (Config, error) {
+	c := New(opts...)
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
 // AdjustSkillsWithDraws returns the new skill level distribution for all provided
 // players based on game configuration and draw status.
 // For a N-player game, the draws parameter should have length n-1, where draws[i]
 // represents whether player[i] and player[i+1] are in draw.
+//
+// The new skills are not checked, use Rate to get an error instead of a skill
+// that is not finite when a player has an improper skill or the inference
+// fails numerically.
 func (ts Config) AdjustSkillsWithDraws(players []Player, draws []bool) (newSkills []Player, probability float64) {
 	// panic if draws slice length is not as expected
 	if len(draws) != len(players)-1 {
@@ -122,25 +206,9 @@ func (ts Config) AdjustSkillsWithDraws(players []Player, draws []bool) (newSkill
 			len(players)-1, len(draws)))
 	}
 
-	// TODO: Rewrite the distribution bag and simplify the factor list as well
-	prior := gaussian.NewFromPrecision(0, 0)
-	varBag := collection.NewDistributionBag(prior)
-
-	skillFactors, skillIndex, factorList := buildSkillFactors(ts, players, draws, varBag)
-
-	sched := buildSkillFactorSchedule(len(players), skillFactors, loopMaxDelta)
-
-	// delta
-	_ = schedule.Run(sched, -1)
+	r, _ := ts.rate(players, draws)
 
-	logZ := factorList.LogNormalization()
-	probability = math.Exp(logZ)
-
-	for _, id := range skillIndex {
-		newSkills = append(newSkills, Player{Gaussian: varBag.Get(id)})
-	}
-
-	return newSkills, probability
+	return r.Players, r.Probability()
 }
 
 // AdjustSkills returns the new skill level distribution for all provided
@@ -157,17 +225,82 @@ func (ts Config) AdjustSkills(players []Player, draw bool) (newSkills []Player,
 	return ts.AdjustSkillsWithDraws(players, draws)
 }
 
+// AdjustIdentifiedSkillsWithDraws works like AdjustSkillsWithDraws but
+// returns the identified players with their new skill levels. Each returned
+// player has its game count incremented, LastPlayed set to playedAt and
+// Provisional cleared once the configured number of provisional games has
+// been played.
+//
+// The players are rated by Rate, an error is returned instead of a panic or
+// a skill that is not finite, see Rate.
+func (ts Config) AdjustIdentifiedSkillsWithDraws(players []IdentifiedPlayer, draws []bool, playedAt time.Time) (newPlayers []IdentifiedPlayer, probability float64, err error) {
+	r, err := ts.Rate(identifiedPlayerSkills(players), draws)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, p := range players {
+		p.Player = r.Players[i]
+		p.GamesPlayed++
+		p.LastPlayed = playedAt
+		p.Provisional = p.GamesPlayed < ts.provisionalGames
+		newPlayers = append(newPlayers, p)
+	}
+
+	return newPlayers, r.Probability(), nil
+}
+
+// AdjustIdentifiedSkills works like AdjustSkills but returns the identified
+// players with their new skill levels, see AdjustIdentifiedSkillsWithDraws.
+func (ts Config) AdjustIdentifiedSkills(players []IdentifiedPlayer, draw bool, playedAt time.Time) (newPlayers []IdentifiedPlayer, probability float64, err error) {
+	if len(players) < 2 {
+		return nil, 0, errTooFewPlayers
+	}
+	draws := make([]bool, len(players)-1)
+	for i := range draws {
+		draws[i] = draw
+	}
+
+	return ts.AdjustIdentifiedSkillsWithDraws(players, draws, playedAt)
+}
+
 // MatchQuality returns a float representing the quality of the match-up
-// between players.
+// between players, each player competing on its own (free-for-all). The
+// quality is the probability of a draw relative to the highest possible
+// draw probability, a value between zero and one.
 //
-// Only two player match quality is supported at this time. Minus one is
-// returned if the match-up is unsupported.
+// Minus one is returned if fewer than two players are provided.
 func (ts Config) MatchQuality(players []Player) float64 {
-	if len(players) > 2 {
+	if len(players) < 2 {
+		return -1
+	}
+	if len(players) == 2 {
+		return calculate2PlayerMatchQuality(ts, players[0], players[1])
+	}
+
+	teams := make([][]Player, len(players))
+	for i, p := range players {
+		teams[i] = []Player{p}
+	}
+	return calculateTeamMatchQuality(ts, teams)
+}
+
+// TeamMatchQuality returns a float representing the quality of the match-up
+// between teams of players, see MatchQuality.
+//
+// Minus one is returned if fewer than two teams are provided or if any of
+// the teams is empty.
+func (ts Config) TeamMatchQuality(teams [][]Player) float64 {
+	if len(teams) < 2 {
 		return -1
 	}
+	for _, t := range teams {
+		if len(t) == 0 {
+			return -1
+		}
+	}
 
-	return calculate2PlayerMatchQuality(ts, players[0], players[1])
+	return calculateTeamMatchQuality(ts, teams)
 }
 
 // NewPlayer returns a new player with the mu and sigma from the game
@@ -176,6 +309,15 @@ func (ts Config) NewPlayer() Player {
 	return NewPlayer(ts.mu, ts.sigma)
 }
 
+// NewIdentifiedPlayer returns a new identified player with the mu and sigma
+// from the game configuration. The player is provisional unless the
+// configuration has no provisional games.
+func (ts Config) NewIdentifiedPlayer(id string) IdentifiedPlayer {
+	p := NewIdentifiedPlayer(id, ts.NewPlayer())
+	p.Provisional = p.GamesPlayed < ts.provisionalGames
+	return p
+}
+
 // TrueSkill returns the conservative TrueSkill of a player. The maximum
 // TrueSkill is two times mu, in the default configuration a value between
 // zero and fifty is returned.
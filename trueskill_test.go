@@ -1,7 +1,9 @@
 package trueskill
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/mafredri/go-trueskill/mathextra"
 )
@@ -254,10 +256,53 @@ func TestTrueSkill_MatchQuality_HeadToHead(t *testing.T) {
 		t.Errorf("Probability == %.1f, want %.1f", matchQuality, wantMatchQuality)
 	}
 
-	players = append(players, ts.NewPlayer())
-	matchQuality = ts.MatchQuality(players)
+	matchQuality = ts.MatchQuality(players[:1])
 	if matchQuality != -1 {
-		t.Errorf("bad match quality for >2 players; got %v, want %v", matchQuality, -1)
+		t.Errorf("bad match quality for <2 players; got %v, want %v", matchQuality, -1)
+	}
+}
+
+func TestTrueSkill_MatchQuality(t *testing.T) {
+	// Values taken from moserware/Skills.
+	ts := New()
+	p := ts.NewPlayer
+
+	for _, tt := range []struct {
+		name  string
+		teams [][]Player
+		want  float64
+	}{
+		{"HeadToHead", [][]Player{{p()}, {p()}}, 0.447},
+		{"3PFreeForAll", [][]Player{{p()}, {p()}, {p()}}, 0.200},
+		{"4PFreeForAll", [][]Player{{p()}, {p()}, {p()}, {p()}}, 0.089},
+		{"8PFreeForAll", [][]Player{{p()}, {p()}, {p()}, {p()}, {p()}, {p()}, {p()}, {p()}}, 0.004},
+		{"OneOnTwo", [][]Player{{p()}, {p(), p()}}, 0.135},
+		{"TwoOnTwo", [][]Player{{p(), p()}, {p(), p()}}, 0.447},
+		{"TwoOnTwoUnbalanced", [][]Player{
+			{NewPlayer(20, 8), NewPlayer(25, 6)},
+			{NewPlayer(35, 7), NewPlayer(40, 5)},
+		}, 0.084},
+	} {
+		got := ts.TeamMatchQuality(tt.teams)
+		if !mathextra.Float64AlmostEq(got, tt.want, 5e-4) {
+			t.Errorf("%s: TeamMatchQuality() == %.3f, want %.3f", tt.name, got, tt.want)
+		}
+
+		allSingle := true
+		var players []Player
+		for _, team := range tt.teams {
+			allSingle = allSingle && len(team) == 1
+			players = append(players, team...)
+		}
+		if allSingle {
+			if got := ts.MatchQuality(players); !mathextra.Float64AlmostEq(got, tt.want, 5e-4) {
+				t.Errorf("%s: MatchQuality() == %.3f, want %.3f", tt.name, got, tt.want)
+			}
+		}
+	}
+
+	if got := ts.TeamMatchQuality([][]Player{{p()}, {}}); got != -1 {
+		t.Errorf("TeamMatchQuality() with empty team == %v, want -1", got)
 	}
 }
 
@@ -276,3 +321,135 @@ func TestTrueSkillForPlayer(t *testing.T) {
 		t.Errorf("wrong trueskill for new player; got %v, want %v", skill, 27)
 	}
 }
+
+func TestTrueSkill_IdentifiedPlayers(t *testing.T) {
+	wantSkill := []float64{
+		29.3958320199992000, 7.1714755873261900,
+		20.6041679800008000, 7.1714755873261900,
+	}
+
+	ts := New(ProvisionalGames(2))
+	playedAt := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	players := []IdentifiedPlayer{ts.NewIdentifiedPlayer("alice"), ts.NewIdentifiedPlayer("bob")}
+	players[1].GamesPlayed = 1
+
+	newPlayers, _, err := ts.AdjustIdentifiedSkills(players, false, playedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testPlayerSkills(t, identifiedPlayerSkills(newPlayers), wantSkill)
+
+	for i, want := range []struct {
+		id          string
+		games       int
+		provisional bool
+	}{
+		{"alice", 1, true},
+		{"bob", 2, false},
+	} {
+		p := newPlayers[i]
+		if p.ID != want.id {
+			t.Errorf("p%d.ID == %q, want %q", i, p.ID, want.id)
+		}
+		if p.GamesPlayed != want.games {
+			t.Errorf("p%d.GamesPlayed == %d, want %d", i, p.GamesPlayed, want.games)
+		}
+		if p.Provisional != want.provisional {
+			t.Errorf("p%d.Provisional == %t, want %t", i, p.Provisional, want.provisional)
+		}
+		if !p.LastPlayed.Equal(playedAt) {
+			t.Errorf("p%d.LastPlayed == %v, want %v", i, p.LastPlayed, playedAt)
+		}
+	}
+}
+
+func TestTrueSkill_IdentifiedPlayersErrors(t *testing.T) {
+	ts := New()
+	playedAt := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, _, err := ts.AdjustIdentifiedSkills([]IdentifiedPlayer{ts.NewIdentifiedPlayer("alice")}, false, playedAt); err == nil {
+		t.Error("AdjustIdentifiedSkills() with one player did not return an error")
+	}
+	players := []IdentifiedPlayer{
+		NewIdentifiedPlayer("alice", NewPlayer(0, 1)),
+		NewIdentifiedPlayer("bob", NewPlayer(1e10, 1)),
+	}
+	if _, _, err := ts.AdjustIdentifiedSkills(players, false, playedAt); err == nil {
+		t.Error("AdjustIdentifiedSkills() with a failed truncation did not return an error")
+	}
+	players[1].Player = Player{}
+	if _, _, err := ts.AdjustIdentifiedSkillsWithDraws(players, []bool{false}, playedAt); err == nil {
+		t.Error("AdjustIdentifiedSkillsWithDraws() with an improper skill did not return an error")
+	}
+}
+
+func TestTrueSkill_NewIdentifiedPlayerProvisional(t *testing.T) {
+	if p := New().NewIdentifiedPlayer("alice"); !p.Provisional {
+		t.Error("NewIdentifiedPlayer().Provisional == false, want true")
+	}
+	if p := New(ProvisionalGames(0)).NewIdentifiedPlayer("alice"); p.Provisional {
+		t.Error("NewIdentifiedPlayer().Provisional with ProvisionalGames(0) == true, want false")
+	}
+}
+
+func TestConfigGetters(t *testing.T) {
+	drawProbability, err := DrawProbability(25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := New(Mu(200), Sigma(64), Beta(32), Tau(0.5), drawProbability, ProvisionalGames(5))
+
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Mu", ts.Mu(), 200},
+		{"Sigma", ts.Sigma(), 64},
+		{"Beta", ts.Beta(), 32},
+		{"Tau", ts.Tau(), 0.5},
+		{"DrawProbability", ts.DrawProbability(), 25},
+		{"ProvisionalGames", float64(ts.ProvisionalGames()), 5},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s() == %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := New().Validate(); err != nil {
+		t.Errorf("New().Validate() == %v, want nil", err)
+	}
+	if _, err := NewChecked(Tau(0), DrawProbabilityZero()); err != nil {
+		t.Errorf("NewChecked(Tau(0), DrawProbabilityZero()) error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		opt  Option
+	}{
+		{"Mu(NaN)", Mu(math.NaN())},
+		{"Mu(Inf)", Mu(math.Inf(1))},
+		{"Sigma(0)", Sigma(0)},
+		{"Sigma(-1)", Sigma(-1)},
+		{"Sigma(NaN)", Sigma(math.NaN())},
+		{"Beta(0)", Beta(0)},
+		{"Beta(NaN)", Beta(math.NaN())},
+		{"Tau(-1)", Tau(-1)},
+		{"Tau(NaN)", Tau(math.NaN())},
+		{"ProvisionalGames(-1)", ProvisionalGames(-1)},
+	} {
+		if err := New(tt.opt).Validate(); err == nil {
+			t.Errorf("New(%s).Validate() did not return an error", tt.name)
+		}
+		if _, err := NewChecked(tt.opt); err == nil {
+			t.Errorf("NewChecked(%s) did not return an error", tt.name)
+		}
+	}
+
+	if _, err := DrawProbability(math.NaN()); err == nil {
+		t.Error("DrawProbability(NaN) did not return an error")
+	}
+}